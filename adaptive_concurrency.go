@@ -0,0 +1,158 @@
+package concurrent_task_pool
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrency 自适应并发数控制器的配置
+// 为任务池设定该控制器后，任务池会在每个统计窗口结束时，根据窗口内任务的执行耗时和失败率，在最小值和最大值之间自动调整任务并发数：
+//   - 失败率超过阈值，或者平均执行耗时相比观测到的最低平均执行耗时出现尖峰时，并发数乘性减少
+//   - 否则，并发数加性增加
+type AdaptiveConcurrency struct {
+	// 任务并发数的最小值，小于1时视为1
+	MinConcurrency int
+	// 任务并发数的最大值，小于最小值时视为最小值
+	MaxConcurrency int
+	// 统计窗口的时长，每个窗口结束时调整一次并发数，小于等于0时为1秒
+	Window time.Duration
+	// 每次加性增加的并发数，小于1时视为1
+	IncreaseStep int
+	// 每次乘性减少时并发数乘以的比例，取值范围为0-1，例如设为0.5时并发数减半
+	DecreaseFactor float64
+	// 失败率阈值，取值范围为0-1，窗口内任务的失败率超过该值时减少并发数
+	ErrorRateThreshold float64
+	// 执行耗时尖峰的判断倍数，窗口内任务的平均执行耗时超过观测到的最低平均执行耗时的该倍数时，视为出现尖峰并减少并发数
+	// 小于等于1时不根据执行耗时调整并发数
+	LatencyTolerance float64
+}
+
+// NewAdaptiveConcurrency 创建一个自适应并发数控制器的配置
+// 统计窗口为1秒，每次增加1个并发数，减少时并发数减半，失败率超过10%或者平均执行耗时超过最低值的2倍时减少并发数
+//
+//   - minConcurrency 任务并发数的最小值
+//   - maxConcurrency 任务并发数的最大值
+//
+// 返回自适应并发数控制器配置对象指针
+func NewAdaptiveConcurrency(minConcurrency, maxConcurrency int) *AdaptiveConcurrency {
+	return &AdaptiveConcurrency{
+		MinConcurrency:     minConcurrency,
+		MaxConcurrency:     maxConcurrency,
+		Window:             time.Second,
+		IncreaseStep:       1,
+		DecreaseFactor:     0.5,
+		ErrorRateThreshold: 0.1,
+		LatencyTolerance:   2,
+	}
+}
+
+// 将并发数限制在最小值和最大值之间
+//
+//   - concurrent 并发数
+//
+// 返回限制后的并发数
+func (controller *AdaptiveConcurrency) clamp(concurrent int) int {
+	minConcurrency := controller.MinConcurrency
+	if minConcurrency < 1 {
+		minConcurrency = 1
+	}
+	maxConcurrency := controller.MaxConcurrency
+	if maxConcurrency < minConcurrency {
+		maxConcurrency = minConcurrency
+	}
+	if concurrent < minConcurrency {
+		return minConcurrency
+	}
+	if concurrent > maxConcurrency {
+		return maxConcurrency
+	}
+	return concurrent
+}
+
+// 根据一个统计窗口内的观测结果计算新的并发数
+//
+//   - current 当前的并发数
+//   - errorRate 窗口内任务的失败率
+//   - latency 窗口内任务的平均执行耗时
+//   - baseLatency 观测到的最低平均执行耗时
+//
+// 返回新的并发数
+func (controller *AdaptiveConcurrency) next(current int, errorRate float64, latency, baseLatency time.Duration) int {
+	latencySpike := controller.LatencyTolerance > 1 && float64(latency) > float64(baseLatency)*controller.LatencyTolerance
+	if errorRate > controller.ErrorRateThreshold || latencySpike {
+		return controller.clamp(int(float64(current) * controller.DecreaseFactor))
+	}
+	step := controller.IncreaseStep
+	if step < 1 {
+		step = 1
+	}
+	return controller.clamp(current + step)
+}
+
+// 自适应并发数控制器的运行状态，记录当前统计窗口内的观测结果
+type adaptiveState struct {
+	// 控制器配置
+	controller *AdaptiveConcurrency
+	// 当前统计窗口的开始时间
+	windowStart time.Time
+	// 当前窗口内执行完成的任务数
+	count int
+	// 当前窗口内执行失败的任务数
+	failures int
+	// 当前窗口内任务的总执行耗时
+	totalLatency time.Duration
+	// 观测到的最低平均执行耗时，为0表示还未观测
+	baseLatency time.Duration
+	// 锁
+	lock sync.Mutex
+}
+
+// 创建自适应并发数控制器的运行状态
+//
+//   - controller 控制器配置
+//
+// 返回运行状态对象指针
+func newAdaptiveState(controller *AdaptiveConcurrency) *adaptiveState {
+	return &adaptiveState{
+		controller:  controller,
+		windowStart: time.Now(),
+		lock:        sync.Mutex{},
+	}
+}
+
+// 记录一次任务执行的观测结果，若当前统计窗口已结束，则计算新的并发数并开始下一个窗口
+//
+//   - current 当前的并发数
+//   - latency 任务的执行耗时
+//   - failed 任务是否执行失败
+//
+// 返回新的并发数，以及统计窗口是否已结束，窗口未结束时不需要调整并发数
+func (state *adaptiveState) record(current int, latency time.Duration, failed bool) (int, bool) {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	state.count++
+	state.totalLatency += latency
+	if failed {
+		state.failures++
+	}
+	window := state.controller.Window
+	if window <= 0 {
+		window = time.Second
+	}
+	if time.Since(state.windowStart) < window {
+		return current, false
+	}
+	// 窗口结束，计算平均执行耗时和失败率
+	averageLatency := state.totalLatency / time.Duration(state.count)
+	errorRate := float64(state.failures) / float64(state.count)
+	if state.baseLatency == 0 || averageLatency < state.baseLatency {
+		state.baseLatency = averageLatency
+	}
+	next := state.controller.next(current, errorRate, averageLatency, state.baseLatency)
+	// 开始下一个窗口
+	state.windowStart = time.Now()
+	state.count = 0
+	state.failures = 0
+	state.totalLatency = 0
+	return next, true
+}
\ No newline at end of file
@@ -16,19 +16,23 @@ type arrayQueue[T any] struct {
 	size int
 	// 锁
 	lock sync.RWMutex
+	// 用于阻塞等待元素入队的条件变量，基于写锁
+	cond *sync.Cond
 }
 
 // newArrayQueue 顺序队列构造函数
 //
 // 返回一个空的顺序队列对象指针
 func newArrayQueue[T any]() *arrayQueue[T] {
-	return &arrayQueue[T]{
+	queue := &arrayQueue[T]{
 		// 初始容量为10
 		data:  make([]T, 10),
 		front: 0,
 		size:  0,
 		lock:  sync.RWMutex{},
 	}
+	queue.cond = sync.NewCond(&queue.lock)
+	return queue
 }
 
 // newArrayQueueFromSlice 从一个现有切片创建顺序队列
@@ -43,6 +47,7 @@ func newArrayQueueFromSlice[T any](slice []T) *arrayQueue[T] {
 		size:  len(slice),
 		lock:  sync.RWMutex{},
 	}
+	queue.cond = sync.NewCond(&queue.lock)
 	copy(queue.data, slice)
 	return queue
 }
@@ -54,12 +59,12 @@ func newArrayQueueFromSlice[T any](slice []T) *arrayQueue[T] {
 // 包含全部队列元素的切片副本，长度为targetSize，元素顺序：从队头到队尾
 func (queue *arrayQueue[T]) copy(targetSize int) []T {
 	// 检查大小
-	if queue.size == 0 {
-		return []T{}
-	}
 	if targetSize < queue.size {
 		targetSize = queue.size
 	}
+	if queue.size == 0 {
+		return make([]T, targetSize)
+	}
 	// 获取尾指针
 	rear := queue.getRear()
 	// 创建新切片
@@ -81,8 +86,12 @@ func (queue *arrayQueue[T]) copy(targetSize int) []T {
 
 // 队列扩容
 func (queue *arrayQueue[T]) scale() {
-	// 扩容两倍并复制新元素
-	queue.data = queue.copy(len(queue.data) * 2)
+	// 扩容两倍并复制新元素，容量为0时（例如从空切片创建的队列）扩容至初始容量
+	newSize := len(queue.data) * 2
+	if newSize == 0 {
+		newSize = 10
+	}
+	queue.data = queue.copy(newSize)
 	// 重置指针
 	queue.front = 0
 }
@@ -114,46 +123,59 @@ func (queue *arrayQueue[T]) offer(element T) {
 	// 元素放到队尾指针处
 	queue.data[queue.getRear()] = element
 	queue.size++
+	// 唤醒一个正在等待取出元素的线程
+	queue.cond.Signal()
 }
 
-// 队列头取出一个元素
+// 队列头取出一个元素，需要在持有队列写锁时调用
+// 队列中的零值元素同样是有效的元素，是否取出了元素以返回的布尔值为准
 //
-// 返回队列头元素
-func (queue *arrayQueue[T]) poll() T {
-	queue.lock.Lock()
-	defer queue.lock.Unlock()
-	polledElement := queue.peek()
-	if queue.size != 0 {
+// 返回队列头元素，以及是否成功取出了元素，队列为空时返回false，此时不会取出元素
+func (queue *arrayQueue[T]) poll() (T, bool) {
+	polledElement, ok := queue.peek()
+	if ok {
 		queue.front = (queue.front + 1) % len(queue.data)
 		queue.size--
 	}
-	return polledElement
+	return polledElement, ok
 }
 
-// 查看队头元素，但是不从队列移除
+// 阻塞地从队列头取出一个元素
+// 若队列为空，则会一直等待直到有元素入队，或者stop返回true
+// 当stop的判断条件发生变化时，需要调用 wakeAll 唤醒正在等待的线程
 //
-// 返回队列头元素
-func (queue *arrayQueue[T]) peek() T {
-	if queue.size == 0 {
+// stop 判断是否停止等待的函数，该函数会在持有队列锁时被调用
+//
+// 返回队列头元素，以及是否成功取出了元素，stop返回true时不会取出元素
+func (queue *arrayQueue[T]) take(stop func() bool) (T, bool) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	for queue.size == 0 && !stop() {
+		queue.cond.Wait()
+	}
+	if stop() {
 		var zero T
-		return zero
+		return zero, false
 	}
-	return queue.data[queue.front]
+	return queue.poll()
 }
 
-// 清空队列
-func (queue *arrayQueue[T]) clear() {
+// 唤醒全部正在 take 中等待的线程，使其重新检查停止条件
+func (queue *arrayQueue[T]) wakeAll() {
 	queue.lock.Lock()
 	defer queue.lock.Unlock()
-	queue.size = 0
-	queue.front = 0
+	queue.cond.Broadcast()
 }
 
-// 判断队列是否为空
+// 查看队头元素，但是不从队列移除
 //
-// 为空返回true
-func (queue *arrayQueue[T]) isEmpty() bool {
-	return queue.size == 0
+// 返回队列头元素，以及队列中是否存在元素，队列为空时返回false
+func (queue *arrayQueue[T]) peek() (T, bool) {
+	if queue.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return queue.data[queue.front], true
 }
 
 // 队列转换成切片
@@ -0,0 +1,27 @@
+package concurrent_task_pool
+
+import "sync/atomic"
+
+// atomicFlag 是一个可以被多个线程并发读写的布尔标志
+type atomicFlag struct {
+	// 标志的值，0表示false，1表示true
+	value int32
+}
+
+// 设定标志的值
+//
+// value 要设定的值
+func (flag *atomicFlag) set(value bool) {
+	if value {
+		atomic.StoreInt32(&flag.value, 1)
+		return
+	}
+	atomic.StoreInt32(&flag.value, 0)
+}
+
+// 读取标志的值
+//
+// 返回标志当前的值
+func (flag *atomicFlag) get() bool {
+	return atomic.LoadInt32(&flag.value) == 1
+}
\ No newline at end of file
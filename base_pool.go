@@ -1,16 +1,32 @@
 package concurrent_task_pool
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// 默认的lookup回调函数调用间隔
+const defaultLookupInterval = 100 * time.Millisecond
+
 // 并发任务池的基本类型，包含了一个并发任务池中的全部任务队列、正在运行的任务以及一些状态等等
-type basePool[T comparable] struct {
+type basePool[T any] struct {
 	// 任务并发数，即worker数量，每一个worker负责在一个单独的线程中运行任务
 	// 当队列中任务数量足够时，并发任务池会一直保持有concurrent个任务一直在并发运行
-	concurrent int
+	// 可以在任务池运行期间通过 SetConcurrency 修改，需要原子地读写
+	concurrent int64
+	// 当前的worker数量，需要原子地读写
+	workerCount int64
+	// 创建worker的函数，参数为worker的ID，任务池启动时设定，为nil时表示任务池还未启动
+	spawnWorker func(id int)
+	// 上一个创建的worker的ID，worker的ID从1开始依次递增
+	lastWorkerID int
+	// 保护spawnWorker、lastWorkerID以及worker创建过程的锁
+	workerLock sync.Mutex
 	// 创建worker时的时间间隔
 	// 若设为0则会在开启并发任务池时同时创建完成全部worker
 	// 该属性不影响worker从队列取出任务的速度，仅仅代表任务池初始化时创建worker的间隔
@@ -20,83 +36,1444 @@ type basePool[T comparable] struct {
 	// 否则，当worker每次从任务队列取出任务时，会延迟一段时间再执行任务
 	workerExecuteDelay time.Duration
 	// 存放全部任务的队列
-	taskQueue *arrayQueue[T]
-	// 当前正在执行的全部任务集合
-	runningTasks *mapSet[T]
+	taskQueue taskQueue[T]
+	// 当前正在执行的全部任务条目集合，以任务条目而非任务对象作为元素，值相同的任务同时执行时会被分别记录
+	runningTasks *mapSet[*taskEntry[T]]
+	// 执行失败后正在等待退避时间结束，随后会被放回队列重试的任务集合
+	retryingTasks *mapSet[*taskEntry[T]]
+	// 已经被worker取出，正在等待执行延迟、速率限制或者权重容量的任务集合，视为排队中的任务
+	waitingTasks *mapSet[*taskEntry[T]]
+	// 已经预订了速率限制的令牌，正在等待令牌可用，随后会被放回队列的任务集合
+	throttledTasks *mapSet[*taskEntry[T]]
+	// 全部执行失败的任务记录
+	failedTasks *arrayQueue[*TaskFailure[T]]
+	// 超过重试策略限制，不再重试的死信任务记录
+	deadTasks *arrayQueue[*TaskFailure[T]]
+	// 还未完成的任务数量，包括位于队列中、正在执行以及等待重试的任务
+	// 该值降为0时，说明全部任务执行完成
+	unfinished int64
+	// 排队中的任务数量，即已经提交或者被放回任务队列，但还未开始执行的任务数量，需要原子地读写
+	// 包括任务队列中的任务，以及等待同键任务、分类名额、依赖、执行延迟、速率限制或者权重容量的任务，不包括等待重试的任务
+	queued int64
+	// 下一个提交的任务的序号
+	nextIndex int64
+	// 初始任务列表中的任务数量，即序号小于该值的任务是创建任务池时传入的任务
+	initialCount int
+	// 全部任务执行完成时被关闭的通道
+	done chan struct{}
+	// 确保done通道只被关闭一次
+	doneOnce sync.Once
+	// 任务池执行时，调用lookup回调函数的时间间隔
+	lookupInterval time.Duration
+	// 按照键串行执行任务的调度器，为nil时不限制
+	serializer *keyedLimiter[T]
+	// 按照分类限制并发数的调度器，为nil时不限制
+	bulkhead *keyedLimiter[T]
+	// 任务的依赖关系图，为nil时不启用依赖关系
+	graph *dependencyGraph[T]
+	// 自适应并发数控制器的运行状态，为nil时不自动调整并发数
+	adaptive *adaptiveState
+	// 权重模式下限制正在执行的任务的权重之和的信号量，为nil时不启用权重模式
+	weights *weightedSemaphore
+	// 获取任务权重的函数，为nil时使用任务对象实现的 WeightedTask 接口
+	weight func(task T) int
+	// 全部worker共享的速率限制器，为nil时不限制
+	rateLimiter *rateLimiter[T]
+	// 任务生命周期事件的监听器列表
+	listeners []TaskListener[T]
+	// 任务池的运行指标记录器
+	stats *poolStats
+	// 获取任务ID的函数，用于任务的去重，为nil时每个任务条目均视为不同的任务
+	taskID func(task T) string
+	// 是否启用了优先级队列
+	isPriority bool
+	// 获取任务优先级的函数，为nil时使用任务对象实现的 PriorityTask 接口
+	priority func(task T) int
+	// 每个任务单次执行的默认超时时间，小于等于0表示不限制
+	taskTimeout time.Duration
+	// 整个任务池的截止时间，到达该时间后任务池会被中断，为零值时表示不限制
+	deadline time.Time
+	// 任务的重试策略，为nil时任务执行失败后不会自动重试
+	retryPolicy *RetryPolicy
+	// 任务执行回调函数发生panic时，是否在记录任务失败后继续向上抛出该panic
+	propagatePanic bool
 	// 是否被中断
 	// 当该变量为true时，则会立即停止并发任务池的任务
-	isInterrupt bool
+	isInterrupt atomicFlag
+	// 确保中断事件只被通知一次
+	interruptOnce sync.Once
 	// 是否正在执行自动任务保存
-	isAutoSaving bool
+	isAutoSaving atomicFlag
+	// 是否结束全部worker，当为true时全部worker会在执行完当前任务后立即结束
+	isShutdown atomicFlag
+	// 是否已被暂停，暂停时worker不会从任务队列取出新的任务
+	isPaused atomicFlag
+	// 用于使worker在暂停时阻塞等待的条件变量，其锁保护暂停状态的变化
+	pauseCond *sync.Cond
+	// 是否处于开放模式，开放模式下任务队列为空时任务池也不会结束，直到任务池被关闭
+	isOpen atomicFlag
+	// 是否已被关闭，关闭后任务池不再接收新的任务
+	isClosed atomicFlag
+	// 任务池运行时的上下文，会传递给每个任务的执行回调函数
+	// 当任务池被中断、接收到终止信号或者父上下文被取消时，该上下文会被取消
+	// 该上下文在启动worker之前创建，之后不会再被修改
+	ctx context.Context
+	// 取消任务池上下文的函数
+	cancel context.CancelFunc
+	// 保护cancel的锁，使任务池在启动前后都能够被安全地中断
+	contextLock sync.Mutex
+	// 用于等待全部worker，以及超时后在后台等待任务执行回调函数返回的线程退出
+	workerGroup sync.WaitGroup
+}
+
+// 创建并发任务池的基本类型对象
+//
+//   - concurrent 任务并发数，即worker数量
+//   - createInterval 创建worker时的时间间隔
+//   - executeDelay worker执行每个任务之前的延迟
+//   - taskList 存放全部任务的切片
+//
+// 返回初始化完成的并发任务池基本类型对象
+func newBasePool[T any](concurrent int, createInterval, executeDelay time.Duration, taskList []T) basePool[T] {
+	return basePool[T]{
+		concurrent:         int64(concurrent),
+		workerCount:        0,
+		spawnWorker:        nil,
+		lastWorkerID:       0,
+		workerLock:         sync.Mutex{},
+		taskCreateInterval: createInterval,
+		workerExecuteDelay: executeDelay,
+		taskQueue:          newTaskEntryQueue(taskList),
+		unfinished:         int64(len(taskList)),
+		queued:             int64(len(taskList)),
+		nextIndex:          int64(len(taskList)),
+		initialCount:       len(taskList),
+		done:               make(chan struct{}),
+		doneOnce:           sync.Once{},
+		lookupInterval:     defaultLookupInterval,
+		runningTasks:       newMapSet[*taskEntry[T]](),
+		retryingTasks:      newMapSet[*taskEntry[T]](),
+		throttledTasks:     newMapSet[*taskEntry[T]](),
+		waitingTasks:       newMapSet[*taskEntry[T]](),
+		failedTasks:        newArrayQueue[*TaskFailure[T]](),
+		deadTasks:          newArrayQueue[*TaskFailure[T]](),
+		serializer:         nil,
+		bulkhead:           nil,
+		graph:              nil,
+		adaptive:           nil,
+		weights:            nil,
+		weight:             nil,
+		rateLimiter:        nil,
+		listeners:          nil,
+		stats:              newPoolStats(len(taskList)),
+		taskID:             nil,
+		isPriority:         false,
+		priority:           nil,
+		taskTimeout:        0,
+		deadline:           time.Time{},
+		retryPolicy:        nil,
+		propagatePanic:     false,
+		isInterrupt:        atomicFlag{},
+		interruptOnce:      sync.Once{},
+		isAutoSaving:       atomicFlag{},
+		isShutdown:         atomicFlag{},
+		isPaused:           atomicFlag{},
+		pauseCond:          sync.NewCond(&sync.Mutex{}),
+		isOpen:             atomicFlag{},
+		isClosed:           atomicFlag{},
+		contextLock:        sync.Mutex{},
+		workerGroup:        sync.WaitGroup{},
+	}
+}
+
+// 基于父上下文初始化任务池运行时的上下文
+//
+//   - parent 父上下文，其被取消时任务池的上下文也会被取消
+func (pool *basePool[T]) initContext(parent context.Context) {
+	pool.contextLock.Lock()
+	defer pool.contextLock.Unlock()
+	pool.ctx, pool.cancel = context.WithCancel(parent)
+	pool.stats.throughput.reset(time.Now())
+	// 设定了截止时间时，到达截止时间后取消上下文
+	if !pool.deadline.IsZero() {
+		deadlineContext, cancelDeadline := context.WithDeadline(pool.ctx, pool.deadline)
+		cancel := pool.cancel
+		pool.ctx = deadlineContext
+		pool.cancel = func() {
+			cancelDeadline()
+			cancel()
+		}
+	}
+	// 启动之前就被中断的任务池，直接取消上下文
+	if pool.isInterrupt.get() {
+		pool.cancel()
+	}
+}
+
+// 取消任务池运行时的上下文，使正在执行的任务能够感知到中断
+func (pool *basePool[T]) cancelContext() {
+	pool.contextLock.Lock()
+	defer pool.contextLock.Unlock()
+	if pool.cancel != nil {
+		pool.cancel()
+	}
+}
+
+// 结束全部worker，并唤醒正在等待任务的worker使其退出
+func (pool *basePool[T]) shutdownWorkers() {
+	// 持有worker锁，确保结束之后不会再创建新的worker
+	pool.workerLock.Lock()
+	pool.isShutdown.set(true)
+	pool.workerLock.Unlock()
+	pool.wakeWorkers()
+}
+
+// 唤醒全部正在等待任务或者因暂停而等待的worker，使其重新检查是否应当退出或者继续执行
+func (pool *basePool[T]) wakeWorkers() {
+	pool.pauseCond.L.Lock()
+	pool.pauseCond.Broadcast()
+	pool.pauseCond.L.Unlock()
+	pool.taskQueue.wakeAll()
+}
+
+// 任务池被暂停时，阻塞当前worker直到任务池被恢复
+// 当worker被结束、任务池上下文被取消，或者worker数量超过任务并发数时，也会停止等待
+func (pool *basePool[T]) waitWhilePaused() {
+	pool.pauseCond.L.Lock()
+	defer pool.pauseCond.L.Unlock()
+	for pool.isPaused.get() && !pool.shouldStop() && !pool.isOverstaffed() {
+		pool.pauseCond.Wait()
+	}
+}
+
+// 设定创建worker的函数，并按照创建worker的时间间隔依次创建worker，直到worker数量达到任务并发数
+//
+//   - spawn 创建并启动一个worker的函数，参数为worker的ID
+func (pool *basePool[T]) startWorkers(spawn func(id int)) {
+	pool.workerLock.Lock()
+	pool.spawnWorker = spawn
+	pool.workerLock.Unlock()
+	for pool.addWorker() {
+		if pool.taskCreateInterval > 0 {
+			time.Sleep(pool.taskCreateInterval)
+		}
+	}
+}
+
+// 当worker数量少于任务并发数时，创建一个新的worker
+//
+// 返回是否创建了新的worker，任务池未启动或者已经结束时不会创建
+func (pool *basePool[T]) addWorker() bool {
+	pool.workerLock.Lock()
+	defer pool.workerLock.Unlock()
+	if pool.spawnWorker == nil || pool.shouldStop() || atomic.LoadInt64(&pool.workerCount) >= atomic.LoadInt64(&pool.concurrent) {
+		return false
+	}
+	atomic.AddInt64(&pool.workerCount, 1)
+	pool.workerGroup.Add(1)
+	pool.lastWorkerID++
+	pool.spawnWorker(pool.lastWorkerID)
+	return true
+}
+
+// 判断worker数量是否超过了任务并发数
+//
+// 超过时返回true，此时多出的worker应当退出
+func (pool *basePool[T]) isOverstaffed() bool {
+	return atomic.LoadInt64(&pool.workerCount) > atomic.LoadInt64(&pool.concurrent)
+}
+
+// 尝试使一个worker退出，以使worker数量不超过任务并发数
+//
+// 返回当前worker是否应当退出
+func (pool *basePool[T]) tryRetireWorker() bool {
+	for {
+		count := atomic.LoadInt64(&pool.workerCount)
+		if count <= atomic.LoadInt64(&pool.concurrent) {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&pool.workerCount, count, count-1) {
+			return true
+		}
+	}
+}
+
+// worker从任务队列中取出一个任务条目，任务队列为空或者任务池被暂停时阻塞等待
+// 当worker被结束、任务池上下文被取消，或者worker数量超过任务并发数时，worker需要退出
+//
+// 返回任务条目，以及是否成功取出，返回false时当前worker应当退出
+func (pool *basePool[T]) takeEntry() (*taskEntry[T], bool) {
+	for {
+		pool.waitWhilePaused()
+		entry, ok := pool.taskQueue.take(func() bool {
+			return pool.shouldStop() || pool.isOverstaffed() || pool.isPaused.get()
+		})
+		if ok {
+			// 任务的依赖还未全部执行成功时，该任务会被暂存，依赖执行失败时该任务同样失败
+			if !pool.checkDependencies(entry) {
+				continue
+			}
+			// 任务的键被其它任务持有，或者任务的分类达到并发数限制时，该任务会进入等待列表，继续取出下一个任务
+			if !pool.acquireLimits(entry) {
+				continue
+			}
+			return entry, true
+		}
+		if pool.shouldStop() {
+			atomic.AddInt64(&pool.workerCount, -1)
+			return nil, false
+		}
+		if pool.tryRetireWorker() {
+			return nil, false
+		}
+	}
+}
+
+// 判断worker是否应当停止从任务队列取出任务
+//
+// 当worker被结束或者任务池上下文被取消时，返回true
+func (pool *basePool[T]) shouldStop() bool {
+	return pool.isShutdown.get() || pool.ctx.Err() != nil
+}
+
+// 处理接收到的终止信号，结束全部worker并将任务池标记为中断
+//
+//   - shutdown 自定义的停机逻辑
+func (pool *basePool[T]) handleSignal(shutdown func()) {
+	// 结束全部worker
+	pool.shutdownWorkers()
+	// 执行shutdown回调
+	shutdown()
+	// 标记为中断
+	pool.isInterrupt.set(true)
+	pool.cancelContext()
+}
+
+// 为一个新提交的任务创建任务条目，并分配提交序号
+//
+//   - task 任务对象
+//
+// 返回新的任务条目
+func (pool *basePool[T]) newEntry(task T) *taskEntry[T] {
+	entry := newTaskEntry(task, int(atomic.AddInt64(&pool.nextIndex, 1)-1))
+	entry.priority = pool.priorityOf(task)
+	return entry
+}
+
+// 获取一个任务的优先级
+//
+//   - task 任务对象
+//
+// 返回任务的优先级，未启用优先级队列，或者没有指定优先级函数且任务对象未实现 PriorityTask 接口时返回0
+func (pool *basePool[T]) priorityOf(task T) int {
+	if !pool.isPriority {
+		return 0
+	}
+	if pool.priority != nil {
+		return pool.priority(task)
+	}
+	if priorityTask, ok := any(task).(PriorityTask); ok {
+		return priorityTask.Priority()
+	}
+	return 0
+}
+
+// 提交一个新的任务条目，启用依赖关系时会先将其加入依赖关系图
+//
+//   - entry 任务条目
+//
+// 任务依赖了不存在的任务时返回 ErrDependencyNotFound ，此时不会提交该任务
+func (pool *basePool[T]) submitEntry(entry *taskEntry[T]) error {
+	if pool.graph != nil {
+		if e := pool.graph.register(entry.task); e != nil {
+			return e
+		}
+	}
+	pool.enqueue(entry)
+	atomic.AddInt64(&pool.stats.submitted, 1)
+	pool.emit(func(listener TaskListener[T]) {
+		listener.OnQueued(newTaskEvent(entry, 0))
+	})
+	return nil
+}
+
+// 将一个任务条目放入任务队列，并计入未完成的任务数量以及排队中的任务数量
+//
+//   - entry 任务条目
+func (pool *basePool[T]) enqueue(entry *taskEntry[T]) {
+	atomic.AddInt64(&pool.unfinished, 1)
+	atomic.AddInt64(&pool.queued, 1)
+	pool.taskQueue.offer(entry)
+}
+
+// 标记一个任务条目的一次执行结束，需要在任务可能的重试被放回队列之后调用
+// 当全部任务都执行完成时，会关闭done通道通知任务池
+func (pool *basePool[T]) finish() {
+	if atomic.AddInt64(&pool.unfinished, -1) == 0 {
+		pool.checkDone()
+	}
+}
+
+// 判断任务池是否仍在接收新的任务，即处于开放模式且还未被关闭
+//
+// 仍在接收新的任务时返回true
+func (pool *basePool[T]) isAccepting() bool {
+	return pool.isOpen.get() && !pool.isClosed.get()
+}
+
+// 判断任务池是否已经结束，即全部任务执行完成，且不再接收新的任务
+//
+// 任务池结束时返回true
+func (pool *basePool[T]) isFinished() bool {
+	return pool.IsAllDone() && !pool.isAccepting()
+}
+
+// 判断任务池是否已经运行结束，即done通道已被关闭
+// 与 isFinished 不同，还未启动的任务池，以及已被关闭但还在执行剩余任务的任务池都不视为运行结束
+//
+// 任务池运行结束时返回true
+func (pool *basePool[T]) isDone() bool {
+	select {
+	case <-pool.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// 若任务池已经结束，则关闭done通道
+func (pool *basePool[T]) checkDone() {
+	if pool.isFinished() {
+		pool.doneOnce.Do(func() {
+			close(pool.done)
+		})
+	}
+}
+
+// 阻塞等待直到任务池全部任务执行完成，或者任务池上下文被取消
+// 开放模式下，还需要等待任务池被关闭
+// 等待期间，每隔 lookupInterval 调用一次lookup函数
+// 若上下文被取消时任务池还未结束，则会将任务池标记为中断
+//
+//   - lookup 查看任务池状态的函数，可以为nil
+func (pool *basePool[T]) waitDone(lookup func()) {
+	pool.checkDone()
+	// 仅在指定了lookup函数时才创建定时器
+	var tick <-chan time.Time
+	if lookup != nil {
+		ticker := time.NewTicker(pool.lookupInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+		lookup()
+	}
+	for {
+		select {
+		case <-pool.done:
+			return
+		case <-pool.ctx.Done():
+			if !pool.isFinished() {
+				pool.Interrupt()
+			}
+			return
+		case <-tick:
+			lookup()
+		}
+	}
 }
 
 // IsAllDone 返回该并发任务池是否完成了全部任务
-// 任务队列中无任务，且正在执行的任务集合中也没有任务了，说明全部任务完成
+// 任务队列中无任务，正在执行的任务集合中没有任务，且没有等待重试的任务了，说明全部任务完成
 //
 // 当并发任务池全部任务执行完成时，返回true
 func (pool *basePool[T]) IsAllDone() bool {
-	return pool.taskQueue.isEmpty() && pool.runningTasks.size() == 0
+	return atomic.LoadInt64(&pool.unfinished) == 0
+}
+
+// SetLookupInterval 设定任务池执行时调用lookup回调函数的时间间隔，默认为100ms
+//
+//   - interval 调用间隔，小于等于0时使用默认值
+func (pool *basePool[T]) SetLookupInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultLookupInterval
+	}
+	pool.lookupInterval = interval
 }
 
 // Interrupt 中断任务池，立即停止任务池中正在执行的任务
+// 同时会取消传递给任务执行回调函数的上下文
 func (pool *basePool[T]) Interrupt() {
-	pool.isInterrupt = true
+	pool.isInterrupt.set(true)
+	pool.interruptOnce.Do(func() {
+		pool.emit(func(listener TaskListener[T]) {
+			listener.OnInterrupt()
+		})
+	})
+	pool.cancelContext()
 	pool.DisableTaskAutoSave()
 }
 
+// Pause 暂停任务池，暂停后worker不会再从任务队列取出新的任务，正在执行的任务会继续执行直到完成
+// 暂停期间，lookup回调函数、自动任务保存以及终止信号的处理仍然正常工作，也可以继续提交任务，可通过 Resume 恢复任务池
+func (pool *basePool[T]) Pause() {
+	pool.pauseCond.L.Lock()
+	defer pool.pauseCond.L.Unlock()
+	pool.isPaused.set(true)
+}
+
+// Resume 恢复被暂停的任务池，worker会继续从任务队列取出任务执行
+func (pool *basePool[T]) Resume() {
+	pool.pauseCond.L.Lock()
+	pool.isPaused.set(false)
+	pool.pauseCond.L.Unlock()
+	pool.wakeWorkers()
+}
+
+// IsPaused 返回任务池是否已被暂停
+func (pool *basePool[T]) IsPaused() bool {
+	return pool.isPaused.get()
+}
+
 // IsInterrupt 返回任务池对象是否已被中断
 //
 // 如果调用过Interrupt方法，或者任务池接收到终止信号（例如Ctrl + C）之后，该方法返回true
 // 正常完成并结束了全部任务的任务池不视为中断，调用该方法仍返回false
 func (pool *basePool[T]) IsInterrupt() bool {
-	return pool.isInterrupt
+	return pool.isInterrupt.get()
 }
 
 // GetQueuedTaskList 获取并发任务池中的全部位于任务队列中的任务列表
 //
-// 返回当前并发任务池中，位于任务队列中的全部任务（还在排队且未执行的任务）
+// 返回当前并发任务池中，位于任务队列中的全部任务（还在排队且未执行的任务），也包括等待同键任务执行完成、等待分类并发名额、等待依赖执行完成，以及已被取出但还在等待执行延迟、速率限制或者权重容量的任务
 func (pool *basePool[T]) GetQueuedTaskList() []T {
-	return pool.taskQueue.toSlice()
+	return entriesToTasks(pool.getQueuedEntries())
+}
+
+// 获取全部排队中的任务条目，即任务队列中的任务条目，以及等待同键任务执行完成、等待分类并发名额、等待依赖执行完成，或者等待执行延迟、速率限制以及权重容量的任务条目
+// 任务条目在集合之间转移时可能会同时位于两个集合中，每个任务条目只会保留一次
+//
+// 返回排队中的任务条目
+func (pool *basePool[T]) getQueuedEntries() []*taskEntry[T] {
+	entries := pool.taskQueue.toSlice()
+	entries = append(entries, pool.waitingTasks.toSlice()...)
+	entries = append(entries, pool.throttledTasks.toSlice()...)
+	for _, limiter := range pool.keyedLimiters() {
+		entries = append(entries, limiter.waitingEntries()...)
+	}
+	if pool.graph != nil {
+		entries = append(entries, pool.graph.parkedEntries()...)
+	}
+	// 按照任务条目去重
+	entrySet := newMapSet[*taskEntry[T]]()
+	distinct := make([]*taskEntry[T], 0, len(entries))
+	for _, entry := range entries {
+		if !entrySet.contains(entry) {
+			entrySet.add(entry)
+			distinct = append(distinct, entry)
+		}
+	}
+	return distinct
+}
+
+// GetQueuedTaskCountByKey 获取每个键还在排队的任务数量，需要通过 EnableKeyedSerialization 启用按键串行执行
+//
+// 返回每个键排队中的任务数量，包括任务队列中的任务以及等待同键任务执行完成的任务，未启用按键串行执行时返回nil
+func (pool *basePool[T]) GetQueuedTaskCountByKey() map[string]int {
+	if pool.serializer == nil {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, entry := range pool.getQueuedEntries() {
+		counts[pool.serializer.key(entry.task)]++
+	}
+	return counts
+}
+
+// Stats 获取任务池的运行指标快照，包括各类任务的数量、吞吐量以及任务执行耗时的分位数
+// 可以在任务池执行期间或者结束后调用，指标由worker在任务状态变化时持续更新，获取快照时不会复制任务队列
+//
+// 返回当前的运行指标快照
+func (pool *basePool[T]) Stats() *PoolStats {
+	now := time.Now()
+	return &PoolStats{
+		Submitted:     atomic.LoadInt64(&pool.stats.submitted),
+		Succeeded:     atomic.LoadInt64(&pool.stats.succeeded),
+		Failed:        atomic.LoadInt64(&pool.stats.failed),
+		Retried:       atomic.LoadInt64(&pool.stats.retried),
+		Running:       pool.runningTasks.size(),
+		Queued:        int(atomic.LoadInt64(&pool.queued)),
+		Retrying:      pool.retryingTasks.size(),
+		Throughput1s:  pool.stats.throughput.rate(now, 1),
+		Throughput10s: pool.stats.throughput.rate(now, 10),
+		Throughput1m:  pool.stats.throughput.rate(now, throughputWindowSeconds),
+		Latency:       pool.stats.latency.snapshot(),
+	}
 }
 
 // GetRunningTaskList 获取并发任务池中正在执行的任务列表
 //
 // 返回当前并发任务池全部正在执行的任务
 func (pool *basePool[T]) GetRunningTaskList() []T {
-	return pool.runningTasks.toSlice()
+	return entriesToTasks(pool.distinctEntries(pool.runningTasks.toSlice()))
+}
+
+// GetRetryingTaskList 获取并发任务池中执行失败后，正在等待退避时间结束以重试的任务列表
+//
+// 返回当前全部等待重试的任务
+func (pool *basePool[T]) GetRetryingTaskList() []T {
+	return entriesToTasks(pool.retryingTasks.toSlice())
 }
 
-// GetAllTaskList 获取全部任务，即：任务队列中正在排队的任务 + 正在执行的任务
+// GetAllTaskList 获取全部任务，即：任务队列中正在排队的任务 + 正在执行的任务 + 等待重试的任务
 //
 // 返回任务池中全部任务
 func (pool *basePool[T]) GetAllTaskList() []T {
+	return entriesToTasks(pool.getAllEntries())
+}
+
+// 获取全部任务条目，即：任务队列中正在排队的任务 + 正在执行的任务 + 等待重试的任务
+// 每个任务条目只会出现一次，值相同的不同任务条目均会被保留
+//
+// 返回任务池中全部任务条目
+func (pool *basePool[T]) getAllEntries() []*taskEntry[T] {
+	entries := pool.getQueuedEntries()
+	entries = append(entries, pool.runningTasks.toSlice()...)
+	entries = append(entries, pool.retryingTasks.toSlice()...)
+	return pool.distinctEntries(entries)
+}
+
+// 对任务条目去重，相同的任务只会保留第一次出现的任务条目
+// 通过 SetTaskID 设定了任务ID函数时，ID相同的任务视为相同的任务，否则只有同一个任务条目才视为相同的任务，例如重试时同时位于任务队列和等待重试集合中的任务条目
+//
+//   - entries 任务条目切片
+//
+// 返回去重后的任务条目切片，顺序与原切片一致
+func (pool *basePool[T]) distinctEntries(entries []*taskEntry[T]) []*taskEntry[T] {
 	// 使用集合去重
-	// 全部排队任务
-	taskSet := newMapSetFromSlice(pool.GetQueuedTaskList())
-	// 加入正在执行的任务
-	runningTasks := pool.GetRunningTaskList()
-	for _, task := range runningTasks {
-		taskSet.add(task)
+	identitySet := make(map[any]struct{})
+	distinct := make([]*taskEntry[T], 0, len(entries))
+	for _, entry := range entries {
+		identity := pool.identityOf(entry)
+		if _, exists := identitySet[identity]; exists {
+			continue
+		}
+		identitySet[identity] = struct{}{}
+		distinct = append(distinct, entry)
 	}
-	return taskSet.toSlice()
+	return distinct
+}
+
+// 获取任务条目用于去重的标识
+//
+//   - entry 任务条目
+//
+// 设定了任务ID函数时返回任务ID，否则返回任务条目本身
+func (pool *basePool[T]) identityOf(entry *taskEntry[T]) any {
+	if pool.taskID != nil {
+		return pool.taskID(entry.task)
+	}
+	return entry
+}
+
+// SetTaskID 设定获取任务ID的函数，需要在启动任务池之前调用
+// 设定后，获取任务列表以及保存任务时，ID相同的任务会被视为相同的任务而去重
+// 启用依赖关系且没有指定获取任务ID的函数时，也会使用该函数获取任务ID
+//
+//   - id 获取任务ID的函数，为nil时不会对值相同的任务去重，每次提交的任务均会被单独记录
+func (pool *basePool[T]) SetTaskID(id func(task T) string) {
+	pool.taskID = id
+}
+
+// GetFailedTaskList 获取并发任务池中执行失败的任务列表
+//
+// 返回全部执行回调函数返回了错误的任务，若设定了重试策略，则只包含重试后仍然失败的任务
+func (pool *basePool[T]) GetFailedTaskList() []T {
+	return failuresToTasks(pool.failedTasks.toSlice())
+}
+
+// GetFailureList 获取并发任务池中全部任务的失败记录
+//
+// 返回全部失败记录，包含失败的任务对象及其错误，顺序为任务失败的先后顺序
+func (pool *basePool[T]) GetFailureList() []*TaskFailure[T] {
+	return pool.failedTasks.toSlice()
+}
+
+// 获取任务池中已启用的全部按键限制的调度器
+//
+// 返回调度器切片，依次为按键串行执行的调度器以及按分类限制并发数的调度器
+func (pool *basePool[T]) keyedLimiters() []*keyedLimiter[T] {
+	limiters := make([]*keyedLimiter[T], 0, 2)
+	if pool.serializer != nil {
+		limiters = append(limiters, pool.serializer)
+	}
+	if pool.bulkhead != nil {
+		limiters = append(limiters, pool.bulkhead)
+	}
+	return limiters
+}
+
+// 使任务条目依次取得全部按键限制的调度器的名额
+// 未取得某个调度器的名额时，任务条目会进入该调度器的等待列表，并在取得名额后被放回任务队列，此时已取得的名额会被保留
+//
+//   - entry 取出的任务条目
+//
+// 全部名额均已取得时返回true，可以执行该任务
+func (pool *basePool[T]) acquireLimits(entry *taskEntry[T]) bool {
+	for _, limiter := range pool.keyedLimiters() {
+		if !limiter.acquire(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// 任务执行完成后，释放任务条目占用的权重以及持有的全部名额，并将接下来持有名额的任务条目放回任务队列
+// 需要在任务可能的重试被放回队列之前调用，使同键的任务按照顺序执行
+//
+//   - entry 执行完成的任务条目
+func (pool *basePool[T]) releaseLimits(entry *taskEntry[T]) {
+	pool.releaseWeight(entry)
+	for _, limiter := range pool.keyedLimiters() {
+		if next := limiter.release(entry); next != nil {
+			pool.taskQueue.offer(next)
+		}
+	}
+}
+
+// worker取出任务之后、执行任务之前，等待执行延迟、速率限制以及权重模式的剩余容量，可以执行时将任务条目存入正在执行的任务集合
+// 等待期间任务条目位于等待执行的任务集合中，视为排队中的任务，而不是正在执行的任务
+//
+//   - entry 即将执行的任务条目
+//
+// 返回是否可以执行该任务
+func (pool *basePool[T]) waitForExecution(entry *taskEntry[T]) bool {
+	pool.waitingTasks.add(entry)
+	defer pool.waitingTasks.remove(entry)
+	if !pool.awaitPermits(entry) {
+		return false
+	}
+	pool.runningTasks.add(entry)
+	atomic.AddInt64(&pool.queued, -1)
+	return true
+}
+
+// 等待执行延迟、速率限制以及权重模式的剩余容量
+// 若等待期间任务池上下文被取消，则任务条目会被放回任务队列，且不计入执行次数
+// 速率限制的令牌不足时，worker不会等待，而是预订之后生成的令牌，并在令牌可用时将任务条目放回任务队列，期间worker可以执行其它任务
+//
+//   - entry 即将执行的任务条目
+//
+// 返回是否可以执行该任务
+func (pool *basePool[T]) awaitPermits(entry *taskEntry[T]) bool {
+	if pool.workerExecuteDelay > 0 {
+		time.Sleep(pool.workerExecuteDelay)
+	}
+	if pool.rateLimiter != nil {
+		if entry.permitted {
+			entry.permitted = false
+		} else if delay := pool.rateLimiter.reserve(entry.task); delay > 0 {
+			pool.throttle(entry, delay)
+			return false
+		}
+	}
+	if pool.weights != nil {
+		weight := pool.weightOf(entry.task)
+		if pool.weights.acquire(pool.ctx, weight) != nil {
+			return pool.requeue(entry)
+		}
+		entry.weight = weight
+	}
+	return true
+}
+
+// 将速率限制的令牌不足的任务条目暂存，在预订的令牌可用时放回任务队列，且不计入执行次数
+// 暂存期间任务条目仍然持有按键串行执行以及分类的名额，放回任务队列后被取出时无需再次取得令牌
+//
+//   - entry 任务条目
+//   - delay 预订的令牌可用之前需要等待的时长
+func (pool *basePool[T]) throttle(entry *taskEntry[T], delay time.Duration) {
+	entry.attempts--
+	entry.permitted = true
+	pool.throttledTasks.add(entry)
+	time.AfterFunc(delay, func() {
+		pool.taskQueue.offer(entry)
+		pool.throttledTasks.remove(entry)
+	})
+}
+
+// 检查取出的任务条目的依赖是否全部执行成功，存在还未执行完成的依赖时，该任务条目会被暂存，直到依赖全部执行成功后被放回任务队列
+// 存在执行失败的依赖时，该任务会被记录为失败，且不会被执行
+//
+//   - entry 取出的任务条目
+//
+// 返回该任务是否可以执行
+func (pool *basePool[T]) checkDependencies(entry *taskEntry[T]) bool {
+	if pool.graph == nil {
+		return true
+	}
+	ready, failedDependency := pool.graph.check(entry)
+	if failedDependency != "" {
+		pool.failDependent(entry, failedDependency)
+		pool.completeDependencies(entry, false)
+		pool.finish()
+	}
+	return ready
+}
+
+// 将因依赖执行失败而不会被执行的任务记录为失败，该任务不再视为排队中的任务
+//
+//   - entry 任务条目
+//   - dependency 执行失败的依赖的ID
+func (pool *basePool[T]) failDependent(entry *taskEntry[T], dependency string) {
+	atomic.AddInt64(&pool.queued, -1)
+	pool.failUnexecuted(entry, fmt.Errorf("%w：%s", ErrDependencyFailed, dependency))
+}
+
+// 将没有被执行就失败的任务记录为失败，并通知监听器
+//
+//   - entry 任务条目
+//   - e 任务失败的原因
+func (pool *basePool[T]) failUnexecuted(entry *taskEntry[T], e error) {
+	pool.stats.recordSkipped()
+	pool.emit(func(listener TaskListener[T]) {
+		event := newTaskEvent(entry, 0)
+		event.Err = e
+		listener.OnFailure(event)
+	})
+	pool.failedTasks.offer(&TaskFailure[T]{
+		Task:     entry.task,
+		Err:      e,
+		Attempts: entry.attempts,
+	})
+}
+
+// 任务最终执行完成后，在依赖关系图中标记该任务，并将依赖全部执行成功的任务放回任务队列
+// 执行失败时，依赖该任务的暂存任务都会被记录为失败，需要在 finish 之前调用
+//
+//   - entry 执行完成的任务条目
+//   - succeeded 是否执行成功
+func (pool *basePool[T]) completeDependencies(entry *taskEntry[T], succeeded bool) {
+	if pool.graph == nil {
+		return
+	}
+	ready, failed := pool.graph.complete(entry.task, succeeded)
+	for _, readyEntry := range ready {
+		pool.taskQueue.offer(readyEntry)
+	}
+	for failedEntry, dependency := range failed {
+		pool.failDependent(failedEntry, dependency)
+		pool.finish()
+	}
+}
+
+// 将未能执行的任务条目放回任务队列，且不计入执行次数
+//
+//   - entry 任务条目
+//
+// 返回false，表示该任务不会被执行
+func (pool *basePool[T]) requeue(entry *taskEntry[T]) bool {
+	entry.attempts--
+	pool.taskQueue.offer(entry)
+	return false
+}
+
+// 获取一个任务的权重
+//
+//   - task 任务对象
+//
+// 返回任务的权重，没有指定权重函数且任务对象未实现 WeightedTask 接口时返回1，权重小于1时也返回1
+func (pool *basePool[T]) weightOf(task T) int {
+	weight := 1
+	if pool.weight != nil {
+		weight = pool.weight(task)
+	} else if weightedTask, ok := any(task).(WeightedTask); ok {
+		weight = weightedTask.Weight()
+	}
+	if weight < 1 {
+		weight = 1
+	}
+	return weight
+}
+
+// 释放任务条目占用的权重
+//
+//   - entry 任务条目
+func (pool *basePool[T]) releaseWeight(entry *taskEntry[T]) {
+	if pool.weights != nil && entry.weight > 0 {
+		pool.weights.release(entry.weight)
+		entry.weight = 0
+	}
+}
+
+// 将一次任务执行的观测结果提供给自适应并发数控制器，并在统计窗口结束时调整并发数
+//
+//   - latency 任务的执行耗时
+//   - e 任务执行的错误
+func (pool *basePool[T]) observeExecution(latency time.Duration, e error) {
+	if pool.adaptive == nil {
+		return
+	}
+	current := pool.GetConcurrency()
+	if next, ok := pool.adaptive.record(current, latency, e != nil); ok && next != current {
+		pool.SetConcurrency(next)
+	}
+}
+
+// 安全地执行一次任务执行回调函数，回调函数中发生的panic会被恢复并转换为 *PanicError
+//
+//   - run 执行任务的函数
+//
+// 返回任务执行的错误，发生panic时返回 *PanicError
+func (pool *basePool[T]) safeRun(run func() error) (e error) {
+	defer func() {
+		if value := recover(); value != nil {
+			e = &PanicError{
+				Value: value,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	return run()
+}
+
+// 若任务执行时发生了panic，并且开启了panic传播，则继续向上抛出该panic
+// 需要在任务的失败已被处理，且任务已从正在执行的任务集合中移除后调用
+//
+//   - e 任务执行的错误
+func (pool *basePool[T]) propagateIfPanic(e error) {
+	if panicError, ok := e.(*PanicError); ok && pool.propagatePanic {
+		panic(panicError)
+	}
+}
+
+// 处理一个任务的一次执行结果，并通知监听器
+// 执行失败时，若设定了重试策略且该任务可以重试，则会在退避时间结束后将任务放回队列，否则记录为失败
+//
+//   - entry 执行完成的任务条目
+//   - workerID 执行该任务的worker的ID
+//   - duration 本次执行任务的耗时
+//   - e 任务执行返回的错误
+//
+// 任务最终执行完成（执行成功或者被记录为失败）时返回true，任务会被重试时返回false
+func (pool *basePool[T]) completeExecution(entry *taskEntry[T], workerID int, duration time.Duration, e error) bool {
+	event := newTaskEvent(entry, workerID)
+	event.Duration = duration
+	event.Err = e
+	if e == nil {
+		pool.stats.recordExecution(duration, true, false)
+		pool.emit(func(listener TaskListener[T]) {
+			listener.OnSuccess(event)
+		})
+		return true
+	}
+	failure := &TaskFailure[T]{
+		Task:     entry.task,
+		Err:      e,
+		Attempts: entry.attempts,
+	}
+	if pool.retryPolicy != nil {
+		if pool.retryPolicy.shouldRetry(entry.attempts, e) {
+			pool.stats.recordExecution(duration, false, true)
+			pool.emit(func(listener TaskListener[T]) {
+				listener.OnRetry(event)
+			})
+			pool.scheduleRetry(entry, pool.retryPolicy.backoff(entry.attempts))
+			return false
+		}
+		// 不再重试的任务放入死信任务列表
+		pool.deadTasks.offer(failure)
+	}
+	pool.stats.recordExecution(duration, false, false)
+	pool.emit(func(listener TaskListener[T]) {
+		listener.OnFailure(event)
+	})
+	pool.failedTasks.offer(failure)
+	return true
+}
+
+// 在退避时间结束后将任务放回任务队列重试
+//
+//   - entry 要重试的任务条目
+//   - delay 退避时间，为0时立即放回队列
+func (pool *basePool[T]) scheduleRetry(entry *taskEntry[T], delay time.Duration) {
+	if delay <= 0 {
+		pool.enqueue(entry)
+		return
+	}
+	// 等待期间任务同样计入未完成的任务，并位于等待重试的集合中
+	atomic.AddInt64(&pool.unfinished, 1)
+	pool.retryingTasks.add(entry)
+	time.AfterFunc(delay, func() {
+		atomic.AddInt64(&pool.queued, 1)
+		pool.taskQueue.offer(entry)
+		pool.retryingTasks.remove(entry)
+	})
+}
+
+// 依次调用每个监听器的通知函数，未注册监听器时不做任何操作
+//
+//   - notify 通知一个监听器的函数
+func (pool *basePool[T]) emit(notify func(listener TaskListener[T])) {
+	for _, listener := range pool.listeners {
+		notify(listener)
+	}
+}
+
+// 通知监听器worker开始执行一个任务
+//
+//   - entry 即将执行的任务条目
+//   - workerID 执行该任务的worker的ID
+func (pool *basePool[T]) notifyStart(entry *taskEntry[T], workerID int) {
+	pool.emit(func(listener TaskListener[T]) {
+		listener.OnStart(newTaskEvent(entry, workerID))
+	})
+}
+
+// 任务池启动时，为初始任务列表中仍在任务队列中的任务通知监听器任务入队
+func (pool *basePool[T]) notifyInitialQueued() {
+	if len(pool.listeners) == 0 {
+		return
+	}
+	for _, entry := range pool.getQueuedEntries() {
+		if entry.index < pool.initialCount {
+			pool.emit(func(listener TaskListener[T]) {
+				listener.OnQueued(newTaskEvent(entry, 0))
+			})
+		}
+	}
+}
+
+// 通知监听器任务池运行结束
+//
+//   - e 任务池启动方法返回的错误
+func (pool *basePool[T]) notifyDone(e error) {
+	pool.emit(func(listener TaskListener[T]) {
+		listener.OnPoolDone(e)
+	})
+}
+
+// AddListener 添加一个任务生命周期事件的监听器，需要在启动任务池之前调用
+// 可以添加多个监听器，事件发生时会按照添加的顺序依次调用
+//
+//   - listener 任务事件监听器，只需要监听部分事件时可以嵌入 BaseTaskListener
+func (pool *basePool[T]) AddListener(listener TaskListener[T]) {
+	pool.listeners = append(pool.listeners, listener)
+}
+
+// 将全部任务失败记录聚合为一个错误
+//
+// 若不存在失败的任务，返回nil，否则返回 *PoolError
+func (pool *basePool[T]) aggregateError() error {
+	failures := pool.failedTasks.toSlice()
+	if len(failures) == 0 {
+		return nil
+	}
+	return &PoolError[T]{Failures: failures}
+}
+
+// EnableKeyedSerialization 启用按键串行执行，需要在启动任务池之前调用
+// 启用后，键相同的任务不会被同时执行，而是按照提交的顺序依次执行，键不同的任务仍然并发执行，可用于避免同时操作同一个文件或者账户
+// 等待同键任务执行完成的任务仍视为排队中的任务，可通过 GetQueuedTaskList 以及 GetQueuedTaskCountByKey 查看
+//
+//   - key 获取任务的键的函数
+func (pool *basePool[T]) EnableKeyedSerialization(key func(task T) string) {
+	pool.serializer = newKeyedLimiter(key, func(key string) int {
+		return 1
+	})
+}
+
+// SetWeightedCapacity 启用权重模式，需要在启动任务池之前调用
+// 启用后，每个任务都具有一个权重，只有在正在执行的任务的权重之和加上该任务的权重不超过容量时，该任务才会被执行，例如按照任务所需的内存限制同时执行的任务
+// 权重超过容量的任务会在没有其它任务执行时单独执行，同时执行的任务数量仍然不会超过任务池的并发数
+//
+//   - capacity 容量，小于等于0时不启用权重模式
+//   - weight 获取任务权重的函数，权重小于1时视为1
+//     若指定为nil，则任务对象实现了 WeightedTask 接口时使用其 Weight 方法的返回值，否则权重均为1
+func (pool *basePool[T]) SetWeightedCapacity(capacity int, weight func(task T) int) {
+	if capacity <= 0 {
+		pool.weights = nil
+		pool.weight = nil
+		return
+	}
+	pool.weights = newWeightedSemaphore(capacity)
+	pool.weight = weight
+}
+
+// GetInFlightWeight 获取正在执行的任务的权重之和，需要通过 SetWeightedCapacity 启用权重模式
+//
+// 返回已占用的权重，未启用权重模式时返回0
+func (pool *basePool[T]) GetInFlightWeight() int {
+	if pool.weights == nil {
+		return 0
+	}
+	return pool.weights.used()
+}
+
+// SetCategoryLimits 设定按分类限制的并发数，需要在启动任务池之前调用
+// 每个任务会通过分类函数被划分到一个分类中，同一分类同时执行的任务数量不会超过该分类的并发数，且全部任务同时执行的数量仍然不会超过任务池的并发数
+// 分类达到并发数限制时，worker会跳过该分类的任务，继续执行其它分类的任务，可通过 GetCategoryStats 查看每个分类的执行状态
+//
+//   - classifier 获取任务分类的函数
+//   - limits 每个分类的并发数，不在其中的分类，或者并发数小于等于0的分类不限制并发数
+func (pool *basePool[T]) SetCategoryLimits(classifier func(task T) string, limits map[string]int) {
+	categoryLimits := make(map[string]int, len(limits))
+	for category, limit := range limits {
+		categoryLimits[category] = limit
+	}
+	pool.bulkhead = newKeyedLimiter(classifier, func(category string) int {
+		return categoryLimits[category]
+	})
+}
+
+// GetCategoryStats 获取每个分类的执行状态，需要通过 SetCategoryLimits 设定按分类限制的并发数
+// 占用分类并发名额的任务均视为该分类正在执行的任务，包括已取得名额但还在等待执行延迟、速率限制或者权重容量的任务
+//
+// 返回每个分类的正在执行的任务数、排队中的任务数以及并发数限制，未设定按分类限制的并发数时返回nil
+func (pool *basePool[T]) GetCategoryStats() map[string]*CategoryStats {
+	if pool.bulkhead == nil {
+		return nil
+	}
+	stats := make(map[string]*CategoryStats)
+	statsOf := func(category string) *CategoryStats {
+		categoryStats, ok := stats[category]
+		if !ok {
+			categoryStats = &CategoryStats{Category: category, Limit: pool.bulkhead.limit(category)}
+			stats[category] = categoryStats
+		}
+		return categoryStats
+	}
+	counts, holders := pool.bulkhead.holding()
+	for category, count := range counts {
+		statsOf(category).Running = count
+	}
+	for _, entry := range pool.getQueuedEntries() {
+		if _, ok := holders[entry]; !ok {
+			statsOf(pool.bulkhead.key(entry.task)).Queued++
+		}
+	}
+	return stats
+}
+
+// EnableDependencies 启用任务之间的依赖关系，需要在启动任务池之前调用
+// 启用后，每个任务都具有一个唯一的ID，并可以声明其依赖的任务的ID，只有依赖的任务全部执行成功后，该任务才会被执行
+// 依赖的任务最终执行失败时，直接或者间接依赖该任务的任务都会被记录为失败，错误为 ErrDependencyFailed
+// 任务池启动时会校验依赖关系，存在重复的任务ID、依赖不存在的任务或者循环依赖时，任务池不会启动并返回对应的错误
+//
+//   - id 获取任务ID的函数，为nil时使用 SetTaskID 设定的函数，若也未设定，则任务对象需要实现 DependentTask 接口
+//   - dependencies 获取任务所依赖的全部任务ID的函数，为nil时任务对象需要实现 DependentTask 接口
+func (pool *basePool[T]) EnableDependencies(id func(task T) string, dependencies func(task T) []string) {
+	if id == nil {
+		id = pool.taskID
+	}
+	if id == nil {
+		id = func(task T) string {
+			return any(task).(DependentTask).ID()
+		}
+	}
+	if dependencies == nil {
+		dependencies = func(task T) []string {
+			return any(task).(DependentTask).Dependencies()
+		}
+	}
+	pool.graph = newDependencyGraph(id, dependencies)
+	for _, entry := range pool.taskQueue.toSlice() {
+		_ = pool.graph.register(entry.task)
+	}
+}
+
+// 启动任务池之前校验任务的依赖关系
+//
+// 依赖关系有效或者未启用依赖关系时返回nil
+func (pool *basePool[T]) validateDependencies() error {
+	if pool.graph == nil {
+		return nil
+	}
+	return pool.graph.validate()
+}
+
+// SetAdaptiveConcurrency 设定自适应并发数控制器，需要在启动任务池之前调用
+// 设定后，任务池会根据任务的执行耗时和失败率，在控制器的最小值和最大值之间自动调整任务并发数，可通过 GetConcurrency 获取当前的并发数
+// 创建任务池时指定的并发数会作为初始并发数，并被限制在最小值和最大值之间
+//
+//   - controller 自适应并发数控制器的配置，为nil时不自动调整并发数
+func (pool *basePool[T]) SetAdaptiveConcurrency(controller *AdaptiveConcurrency) {
+	if controller == nil {
+		pool.adaptive = nil
+		return
+	}
+	pool.adaptive = newAdaptiveState(controller)
+	pool.SetConcurrency(controller.clamp(pool.GetConcurrency()))
+}
+
+// SetRateLimit 设定任务池的速率限制，需要在启动任务池之前调用
+// 速率限制基于令牌桶算法，被全部worker共享，因此实际的执行速率与任务并发数无关
+//
+//   - rate 每秒允许开始执行的任务数，小于等于0表示不限制
+//   - burst 允许突发执行的任务数，即令牌桶的容量，小于1时会被设为1
+func (pool *basePool[T]) SetRateLimit(rate float64, burst int) {
+	pool.SetKeyedRateLimit(rate, burst, nil)
+}
+
+// SetKeyedRateLimit 设定按照键分别限制的速率限制，需要在启动任务池之前调用
+// 键相同的任务共享一个令牌桶，例如按照请求的目标主机分别限制速率
+//
+//   - rate 每个键每秒允许开始执行的任务数，小于等于0表示不限制
+//   - burst 每个键允许突发执行的任务数，即令牌桶的容量，小于1时会被设为1
+//   - key 获取任务的键的函数，为nil时全部任务共享一个令牌桶，与 SetRateLimit 相同
+func (pool *basePool[T]) SetKeyedRateLimit(rate float64, burst int, key func(task T) string) {
+	if rate <= 0 {
+		pool.rateLimiter = nil
+		return
+	}
+	pool.rateLimiter = newRateLimiter(rate, burst, key)
+}
+
+// EnablePriorityQueue 启用优先级队列，需要在创建任务池之后、启动任务池之前调用
+// 启用后，任务队列中优先级越大的任务越先被执行，优先级相同的任务按照入队的先后顺序执行，任务池中已有的任务也会按照优先级重新排列
+// 按照重试策略自动重试的任务会保留其原有的优先级，可通过 RetryWithPriority 或者 SubmitWithPriority 指定任务的优先级
+//
+//   - priority 获取任务优先级的函数，值越大越先被执行
+//     若指定为nil，则任务对象实现了 PriorityTask 接口时使用其 Priority 方法的返回值，否则优先级均为0
+func (pool *basePool[T]) EnablePriorityQueue(priority func(task T) int) {
+	pool.isPriority = true
+	pool.priority = priority
+	entries := pool.taskQueue.toSlice()
+	for _, entry := range entries {
+		entry.priority = pool.priorityOf(entry.task)
+	}
+	pool.taskQueue = newPriorityQueue(entries)
+}
+
+// SetConcurrency 设定任务并发数，即worker数量，可以在任务池启动之前或者运行期间调用
+// 任务池运行期间增加并发数时，会立即创建新的worker，减少并发数时，多出的worker会在执行完当前的任务后退出，不会影响任务的执行状态
+//
+//   - concurrent 新的任务并发数，小于1时会被设为1
+func (pool *basePool[T]) SetConcurrency(concurrent int) {
+	if concurrent < 1 {
+		concurrent = 1
+	}
+	atomic.StoreInt64(&pool.concurrent, int64(concurrent))
+	// 创建新的worker
+	for pool.addWorker() {
+	}
+	// 唤醒正在等待任务的worker，使多出的worker退出
+	pool.wakeWorkers()
+}
+
+// GetConcurrency 获取任务池当前设定的任务并发数
+//
+// 返回任务并发数，减少并发数后，实际的worker数量会在多出的worker执行完当前任务后才降低至该值
+func (pool *basePool[T]) GetConcurrency() int {
+	return int(atomic.LoadInt64(&pool.concurrent))
+}
+
+// SetTaskTimeout 设定每个任务单次执行的默认超时时间，需要在启动任务池之前调用
+// 超时后，传递给任务执行回调函数的上下文会被取消，worker不再等待该任务，并将其视为执行失败（ ErrTaskTimeout ），同样适用重试策略
+// 注意若任务执行回调函数不响应上下文的取消，则该回调函数仍会在后台继续运行直到返回，但其结果会被丢弃
+// 任务对象可以通过实现 TimeoutTask 接口为每个任务单独指定超时时间
+//
+//   - timeout 超时时间，小于等于0表示不限制
+func (pool *basePool[T]) SetTaskTimeout(timeout time.Duration) {
+	pool.taskTimeout = timeout
+}
+
+// SetDeadline 设定整个任务池的截止时间，需要在启动任务池之前调用
+// 到达截止时间时若任务池还未结束，则任务池会被中断，传递给任务执行回调函数的上下文也会被取消
+//
+//   - deadline 截止时间，为零值时表示不限制
+func (pool *basePool[T]) SetDeadline(deadline time.Time) {
+	pool.deadline = deadline
+}
+
+// SetRetryPolicy 设定任务池的重试策略，需要在启动任务池之前调用
+// 设定后，当任务执行回调函数返回错误时，任务池会按照该策略自动重试任务，超过重试次数或者错误不可重试的任务会被放入死信任务列表
+//
+//   - policy 重试策略，为nil时表示不自动重试
+func (pool *basePool[T]) SetRetryPolicy(policy *RetryPolicy) {
+	pool.retryPolicy = policy
+}
+
+// SetPanicPropagation 设定任务执行回调函数发生panic时是否继续向上抛出
+// 默认情况下，worker会恢复任务执行时发生的panic，并将其作为 *PanicError 错误记录为任务的失败，同样适用重试策略
+// 若设为true，则会在记录任务失败后重新抛出该panic，使程序立即崩溃
+//
+//   - propagate 是否继续向上抛出panic
+func (pool *basePool[T]) SetPanicPropagation(propagate bool) {
+	pool.propagatePanic = propagate
+}
+
+// GetDeadTaskList 获取死信任务列表，即按照重试策略重试后仍然失败，或者错误不可重试而不再重试的任务
+//
+// 返回全部死信任务
+func (pool *basePool[T]) GetDeadTaskList() []T {
+	return failuresToTasks(pool.deadTasks.toSlice())
+}
+
+// SaveDeadTaskList 将死信任务列表序列化并保存至本地，可用于后续排查或者重新执行
+// 需要将任务对象的必要字段导出，并使用json标签才能够保存
+//
+//   - file 任务文件保存位置
+//
+// 若出现错误，则返回错误对象
+func (pool *basePool[T]) SaveDeadTaskList(file string) error {
+	// 序列化为JSON
+	taskJson, e := json.Marshal(pool.GetDeadTaskList())
+	if e != nil {
+		return e
+	}
+	// 保存
+	return saveDataToFile(taskJson, file)
+}
+
+// Open 将任务池设为开放模式，需要在启动任务池之前调用
+// 开放模式下，任务队列中的任务全部执行完成后，worker仍然会一直等待新的任务，任务池不会结束，可通过 Submit 方法持续地提交任务
+// 直到调用 Close 方法关闭任务池，并执行完剩余的全部任务后，任务池才会结束
+func (pool *basePool[T]) Open() {
+	pool.isOpen.set(true)
+}
+
+// Close 关闭任务池，关闭后任务池不再接收新的任务，并在执行完剩余的全部任务后结束
+// 主要用于结束开放模式的任务池
+func (pool *basePool[T]) Close() {
+	pool.isClosed.set(true)
+	pool.checkDone()
+}
+
+// IsClosed 返回任务池是否已被关闭
+//
+// 调用过 Close 方法，或者任务池已经结束时，返回true
+func (pool *basePool[T]) IsClosed() bool {
+	return pool.isClosed.get()
+}
+
+// Submit 提交一个新的任务到任务池的任务队列中
+// 可以在任务池启动之前或者运行期间调用，开放模式下可以一直提交任务直到任务池被关闭
+//
+//   - task 要提交的任务
+//
+// 任务池已被关闭、已经结束或者已被中断时，返回 ErrPoolClosed
+func (pool *basePool[T]) Submit(task T) error {
+	if pool.isClosed.get() || pool.isInterrupt.get() {
+		return ErrPoolClosed
+	}
+	return pool.submitEntry(pool.newEntry(task))
+}
+
+// SubmitBatch 批量提交任务到任务池的任务队列中，任务会按照切片顺序入队
+//
+//   - taskList 要提交的任务切片
+//
+// 任务池已被关闭、已经结束或者已被中断时，返回 ErrPoolClosed ，此时不会提交任何任务
+func (pool *basePool[T]) SubmitBatch(taskList []T) error {
+	if pool.isClosed.get() || pool.isInterrupt.get() {
+		return ErrPoolClosed
+	}
+	for _, task := range taskList {
+		if e := pool.submitEntry(pool.newEntry(task)); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// SubmitWithPriority 以指定的优先级提交一个新的任务到任务池的任务队列中，指定的优先级会覆盖优先级函数的计算结果
+// 未启用优先级队列时，优先级不会生效，与 Submit 相同
+//
+//   - task 要提交的任务
+//   - priority 任务的优先级，值越大越先被执行
+//
+// 任务池已被关闭、已经结束或者已被中断时，返回 ErrPoolClosed
+func (pool *basePool[T]) SubmitWithPriority(task T, priority int) error {
+	if pool.isClosed.get() || pool.isInterrupt.get() {
+		return ErrPoolClosed
+	}
+	entry := pool.newEntry(task)
+	entry.priority = priority
+	return pool.submitEntry(entry)
 }
 
 // Retry 重试任务，若任务执行失败，可将当前任务对象重新放回并发任务池的任务队列中，使其在后续重新执行
+// 通过该方法手动重试的任务会被视为一个新的任务，不受重试策略的次数限制
+// 与 Submit 不同，已被关闭但还在执行剩余任务的任务池仍然会接收重试的任务
+// 任务池已经运行结束或者已被中断时，重试的任务不会被放回任务队列，而是被记录为失败，错误为 ErrPoolClosed
 //
 // task 要放回任务队列进行重试的任务
 func (pool *basePool[T]) Retry(task T) {
-	pool.taskQueue.offer(task)
+	pool.retryEntry(pool.newEntry(task))
+}
+
+// RetryWithPriority 以指定的优先级重试任务，启用优先级队列时，可以使重试的任务在其它排队的任务之前执行
+// 与 Retry 相同，通过该方法手动重试的任务会被视为一个新的任务，任务池已经运行结束或者已被中断时，重试的任务会被记录为失败
+//
+//   - task 要放回任务队列进行重试的任务
+//   - priority 任务的优先级，值越大越先被执行
+func (pool *basePool[T]) RetryWithPriority(task T, priority int) {
+	entry := pool.newEntry(task)
+	entry.priority = priority
+	pool.retryEntry(entry)
+}
+
+// 提交一个手动重试的任务条目，任务池已经运行结束或者已被中断时，将其记录为失败
+//
+//   - entry 要重试的任务条目
+func (pool *basePool[T]) retryEntry(entry *taskEntry[T]) {
+	if pool.isDone() || pool.isInterrupt.get() {
+		pool.failUnexecuted(entry, ErrPoolClosed)
+		return
+	}
+	_ = pool.submitEntry(entry)
+}
+
+// RestoreTaskList 从保存的任务文件中恢复任务，并提交到任务池的任务队列中
+// 启用了优先级队列时，任务会保留其保存时的优先级，启用了依赖关系时，会还原保存时已经执行成功的任务，使依赖这些任务的任务能够继续执行
+//
+//   - file 保存的任务文件位置
+//
+// 读取任务文件失败时返回对应错误，任务池已被关闭、已经结束或者已被中断时，返回 ErrPoolClosed
+func (pool *basePool[T]) RestoreTaskList(file string) error {
+	taskFile, e := loadTaskFile[T](file)
+	if e != nil {
+		return e
+	}
+	if pool.isClosed.get() || pool.isInterrupt.get() {
+		return ErrPoolClosed
+	}
+	// 还原依赖关系图中已经执行成功的任务
+	if pool.graph != nil {
+		pool.graph.markSucceeded(taskFile.Completed)
+	}
+	for _, savedTask := range taskFile.Tasks {
+		entry := pool.newEntry(savedTask.Task)
+		entry.priority = savedTask.Priority
+		if e = pool.submitEntry(entry); e != nil {
+			return e
+		}
+	}
+	return nil
 }
 
 // SaveTaskList 将并发任务池中的全部任务（包括队列任务和正在执行的任务）序列化并保存至本地
 // 需要将任务对象的必要字段导出，并使用json标签才能够保存
+// 启用了优先级队列时，任务的优先级会被一同保存，启用了依赖关系时，已经执行成功的任务ID会被一同保存，可通过 RestoreTaskList 恢复
 //
 //   - file 任务文件保存位置
 //
 // 若出现错误，则返回错误对象
 func (pool *basePool[T]) SaveTaskList(file string) error {
 	// 序列化为JSON
-	taskJson, e := json.Marshal(pool.GetAllTaskList())
+	var data any = pool.GetAllTaskList()
+	if pool.isPriority || pool.graph != nil {
+		entries := pool.getAllEntries()
+		savedTasks := make([]*SavedTask[T], 0, len(entries))
+		for _, entry := range entries {
+			savedTasks = append(savedTasks, &SavedTask[T]{Task: entry.task, Priority: entry.priority})
+		}
+		taskFile := &savedTaskFile[T]{Tasks: savedTasks}
+		if pool.graph != nil {
+			taskFile.Completed = pool.graph.succeededIDs()
+		}
+		data = taskFile
+	}
+	taskJson, e := json.Marshal(data)
 	if e != nil {
 		return e
 	}
@@ -111,15 +1488,15 @@ func (pool *basePool[T]) SaveTaskList(file string) error {
 //   - interval 自动保存间隔
 func (pool *basePool[T]) EnableTaskAutoSave(file string, interval time.Duration) {
 	// 标记为自动保存
-	pool.isAutoSaving = true
+	pool.isAutoSaving.set(true)
 	// 定时执行逻辑
 	go func() {
-		for pool.isAutoSaving {
+		for pool.isAutoSaving.get() {
 			e := pool.SaveTaskList(file)
 			if e != nil {
 				fmt.Printf("保存任务出现错误：%s\n", e)
 			}
-			if pool.IsAllDone() {
+			if pool.isFinished() {
 				pool.DisableTaskAutoSave()
 				return
 			}
@@ -132,5 +1509,5 @@ func (pool *basePool[T]) EnableTaskAutoSave(file string, interval time.Duration)
 // 在使用 EnableTaskAutoSave 后，若后续不再需要自动保存任务，则可以调用该函数关闭自动保存
 // 此外，任务池全部任务执行完成后或者被中断时，该方法也会被自动调用关闭自动任务保存
 func (pool *basePool[T]) DisableTaskAutoSave() {
-	pool.isAutoSaving = false
+	pool.isAutoSaving.set(false)
 }
\ No newline at end of file
@@ -0,0 +1,291 @@
+package concurrent_task_pool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DependentTask 是可以声明依赖关系的任务
+// 启用依赖关系且没有指定获取任务ID和依赖的函数时，任务对象需要实现该接口
+type DependentTask interface {
+	// ID 返回该任务的唯一标识
+	ID() string
+	// Dependencies 返回该任务所依赖的全部任务的ID，这些任务全部执行成功后该任务才会被执行
+	Dependencies() []string
+}
+
+// 依赖关系图中的任务节点的状态
+type nodeState int
+
+const (
+	// 任务还未执行完成
+	nodePending nodeState = iota
+	// 任务执行成功
+	nodeSucceeded
+	// 任务最终执行失败，或者其依赖的任务执行失败
+	nodeFailed
+)
+
+// 任务的依赖关系图，只有依赖的任务全部执行成功的任务才会被执行
+//
+// worker取出的任务条目存在还未执行完成的依赖时，该任务条目会被暂存，直到其依赖全部执行成功后被放回任务队列
+// 依赖的任务最终执行失败时，暂存的任务条目也会被视为执行失败
+type dependencyGraph[T any] struct {
+	// 获取任务ID的函数
+	id func(task T) string
+	// 获取任务依赖的函数
+	dependencies func(task T) []string
+	// 每个任务的状态
+	states map[string]nodeState
+	// 每个任务所依赖的任务ID
+	requires map[string][]string
+	// 每个任务被哪些任务所依赖
+	dependents map[string][]string
+	// 等待依赖执行完成而被暂存的任务条目
+	parked map[string]*taskEntry[T]
+	// 校验依赖关系图之前发现的重复的任务ID
+	duplicates []string
+	// 是否已经校验过依赖关系图，校验之后提交的任务只能依赖已存在的任务
+	validated bool
+	// 锁
+	lock sync.Mutex
+}
+
+// 创建任务的依赖关系图
+//
+//   - id 获取任务ID的函数
+//   - dependencies 获取任务依赖的函数
+//
+// 返回依赖关系图对象指针
+func newDependencyGraph[T any](id func(task T) string, dependencies func(task T) []string) *dependencyGraph[T] {
+	return &dependencyGraph[T]{
+		id:           id,
+		dependencies: dependencies,
+		states:       make(map[string]nodeState),
+		requires:     make(map[string][]string),
+		dependents:   make(map[string][]string),
+		parked:       make(map[string]*taskEntry[T]),
+		duplicates:   make([]string, 0),
+		validated:    false,
+		lock:         sync.Mutex{},
+	}
+}
+
+// 将一个任务加入依赖关系图，已存在的任务会被重新标记为还未执行完成
+//
+//   - task 任务对象
+//
+// 校验依赖关系图之后，若任务依赖了不存在的任务，则返回 ErrDependencyNotFound ，此时不会加入该任务
+func (graph *dependencyGraph[T]) register(task T) error {
+	id := graph.id(task)
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	if _, ok := graph.requires[id]; ok {
+		if !graph.validated && graph.states[id] == nodePending {
+			graph.duplicates = append(graph.duplicates, id)
+		}
+		graph.states[id] = nodePending
+		return nil
+	}
+	requires := graph.dependencies(task)
+	if graph.validated {
+		for _, dependency := range requires {
+			if _, ok := graph.states[dependency]; !ok {
+				return fmt.Errorf("%w：任务%s依赖的任务%s", ErrDependencyNotFound, id, dependency)
+			}
+		}
+	}
+	graph.states[id] = nodePending
+	graph.requires[id] = requires
+	for _, dependency := range requires {
+		graph.dependents[dependency] = append(graph.dependents[dependency], id)
+	}
+	return nil
+}
+
+// 将已经执行成功的任务标记为成功，用于恢复之前保存的任务时还原依赖关系图的状态
+//
+//   - ids 已经执行成功的任务ID
+func (graph *dependencyGraph[T]) markSucceeded(ids []string) {
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	for _, id := range ids {
+		if _, ok := graph.requires[id]; !ok {
+			graph.requires[id] = nil
+		}
+		graph.states[id] = nodeSucceeded
+	}
+}
+
+// 校验依赖关系图，检查重复的任务ID、依赖不存在的任务以及循环依赖
+//
+// 依赖关系图有效时返回nil，否则返回对应的错误
+func (graph *dependencyGraph[T]) validate() error {
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	if len(graph.duplicates) > 0 {
+		return fmt.Errorf("%w：%s", ErrDuplicateTaskID, strings.Join(graph.duplicates, ", "))
+	}
+	// 按照ID排序，使校验结果稳定
+	ids := make([]string, 0, len(graph.requires))
+	for id := range graph.requires {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, dependency := range graph.requires[id] {
+			if _, ok := graph.states[dependency]; !ok {
+				return fmt.Errorf("%w：任务%s依赖的任务%s", ErrDependencyNotFound, id, dependency)
+			}
+		}
+	}
+	// 深度优先搜索检测环，0表示未访问，1表示正在访问，2表示已访问
+	visited := make(map[string]int)
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch visited[id] {
+		case 1:
+			// 从路径中截取出环
+			start := 0
+			for i, pathID := range path {
+				if pathID == id {
+					start = i
+				}
+			}
+			return fmt.Errorf("%w：%s -> %s", ErrDependencyCycle, strings.Join(path[start:], " -> "), id)
+		case 2:
+			return nil
+		}
+		visited[id] = 1
+		path = append(path, id)
+		for _, dependency := range graph.requires[id] {
+			if e := visit(dependency); e != nil {
+				return e
+			}
+		}
+		path = path[:len(path)-1]
+		visited[id] = 2
+		return nil
+	}
+	for _, id := range ids {
+		if e := visit(id); e != nil {
+			return e
+		}
+	}
+	graph.validated = true
+	return nil
+}
+
+// 检查一个任务条目的依赖是否全部执行成功，存在还未执行完成的依赖时暂存该任务条目
+//
+//   - entry 取出的任务条目
+//
+// 返回该任务是否可以执行，以及执行失败的依赖的ID，存在执行失败的依赖时该任务不应当被执行
+func (graph *dependencyGraph[T]) check(entry *taskEntry[T]) (bool, string) {
+	id := graph.id(entry.task)
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	ready := true
+	for _, dependency := range graph.requires[id] {
+		switch graph.states[dependency] {
+		case nodeFailed:
+			return false, dependency
+		case nodePending:
+			ready = false
+		}
+	}
+	if !ready {
+		graph.parked[id] = entry
+	}
+	return ready, ""
+}
+
+// 标记一个任务执行完成
+// 执行成功时，依赖全部执行成功的暂存任务条目会被取出，执行失败时，直接或者间接依赖该任务的暂存任务条目都会被视为执行失败
+//
+//   - task 执行完成的任务对象
+//   - succeeded 是否执行成功
+//
+// 返回可以执行的任务条目，以及因依赖执行失败而失败的任务条目及其执行失败的依赖的ID
+func (graph *dependencyGraph[T]) complete(task T, succeeded bool) ([]*taskEntry[T], map[*taskEntry[T]]string) {
+	id := graph.id(task)
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	ready := make([]*taskEntry[T], 0)
+	failed := make(map[*taskEntry[T]]string)
+	if succeeded {
+		graph.states[id] = nodeSucceeded
+		for _, dependent := range graph.dependents[id] {
+			entry, ok := graph.parked[dependent]
+			if ok && graph.isReady(dependent) {
+				delete(graph.parked, dependent)
+				ready = append(ready, entry)
+			}
+		}
+		return ready, failed
+	}
+	// 依次将暂存的依赖该任务的任务条目标记为失败，还位于任务队列中的任务会在被取出时标记为失败
+	graph.states[id] = nodeFailed
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range graph.dependents[current] {
+			entry, ok := graph.parked[dependent]
+			if !ok {
+				continue
+			}
+			delete(graph.parked, dependent)
+			graph.states[dependent] = nodeFailed
+			failed[entry] = current
+			queue = append(queue, dependent)
+		}
+	}
+	return ready, failed
+}
+
+// 判断一个任务的依赖是否全部执行成功，需要在持有锁时调用
+//
+//   - id 任务ID
+//
+// 全部执行成功时返回true
+func (graph *dependencyGraph[T]) isReady(id string) bool {
+	for _, dependency := range graph.requires[id] {
+		if graph.states[dependency] != nodeSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// 获取全部暂存的任务条目
+//
+// 返回等待依赖执行完成的任务条目
+func (graph *dependencyGraph[T]) parkedEntries() []*taskEntry[T] {
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	entries := make([]*taskEntry[T], 0, len(graph.parked))
+	for _, entry := range graph.parked {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// 获取全部执行成功的任务ID
+//
+// 返回执行成功的任务ID，按照字典序排列
+func (graph *dependencyGraph[T]) succeededIDs() []string {
+	graph.lock.Lock()
+	defer graph.lock.Unlock()
+	ids := make([]string, 0)
+	for id, state := range graph.states {
+		if state == nodeSucceeded {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
\ No newline at end of file
@@ -2,6 +2,7 @@ package concurrent_task_pool
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
@@ -48,22 +49,84 @@ func readDataFromFile(path string) ([]byte, error) {
 	return io.ReadAll(reader)
 }
 
+// SavedTask 是任务文件中保存的一个任务，包含任务对象及其优先级
+type SavedTask[T any] struct {
+	// 任务对象
+	Task T `json:"task"`
+	// 任务的优先级
+	Priority int `json:"priority"`
+}
+
+// 启用了优先级队列或者依赖关系的任务池所保存的任务文件内容
+type savedTaskFile[T any] struct {
+	// 全部任务，顺序为出队的先后顺序
+	Tasks []*SavedTask[T] `json:"tasks"`
+	// 启用依赖关系时，已经执行成功的任务ID
+	Completed []string `json:"completed,omitempty"`
+}
+
 // LoadTaskFile 从保存的任务文件中读取任务对象
+// 对于启用了优先级队列的任务池保存的任务文件，任务的优先级会被忽略，如需保留优先级请使用 LoadSavedTaskFile
 //
 //   - path 读取保存的任务文件
 //
 // 返回读取并反序列化后的任务对象切片
-func LoadTaskFile[T comparable](path string) ([]T, error) {
+func LoadTaskFile[T any](path string) ([]T, error) {
+	savedTasks, e := LoadSavedTaskFile[T](path)
+	if e != nil {
+		return nil, e
+	}
+	tasks := make([]T, 0, len(savedTasks))
+	for _, savedTask := range savedTasks {
+		tasks = append(tasks, savedTask.Task)
+	}
+	return tasks, nil
+}
+
+// LoadSavedTaskFile 从保存的任务文件中读取任务对象及其优先级
+// 同时支持普通任务池保存的任务文件，此时全部任务的优先级均为0
+//
+//   - path 读取保存的任务文件
+//
+// 返回读取并反序列化后的任务切片，顺序与任务文件中一致
+func LoadSavedTaskFile[T any](path string) ([]*SavedTask[T], error) {
+	taskFile, e := loadTaskFile[T](path)
+	if e != nil {
+		return nil, e
+	}
+	return taskFile.Tasks, nil
+}
+
+// 读取保存的任务文件的全部内容
+// 同时支持普通任务池保存的任务文件，此时全部任务的优先级均为0
+//
+//   - path 读取保存的任务文件
+//
+// 返回读取并反序列化后的任务文件内容
+func loadTaskFile[T any](path string) (*savedTaskFile[T], error) {
 	// 读取文件
 	data, e := readDataFromFile(path)
 	if e != nil {
 		return nil, e
 	}
+	// 优先级队列或者依赖关系保存的任务文件为JSON对象，否则为JSON数组
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var taskFile savedTaskFile[T]
+		e = json.Unmarshal(data, &taskFile)
+		if e != nil {
+			return nil, e
+		}
+		return &taskFile, nil
+	}
 	// 反序列化
 	var tasks []T
 	e = json.Unmarshal(data, &tasks)
 	if e != nil {
 		return nil, e
 	}
-	return tasks, nil
+	savedTasks := make([]*SavedTask[T], 0, len(tasks))
+	for _, task := range tasks {
+		savedTasks = append(savedTasks, &SavedTask[T]{Task: task, Priority: 0})
+	}
+	return &savedTaskFile[T]{Tasks: savedTasks}, nil
 }
\ No newline at end of file
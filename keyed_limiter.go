@@ -0,0 +1,134 @@
+package concurrent_task_pool
+
+import "sync"
+
+// CategoryStats 一个任务分类的执行状态
+type CategoryStats struct {
+	// 分类名称
+	Category string
+	// 该分类正在执行的任务数，即占用该分类并发名额的任务数，因此不会超过并发数限制
+	// 包括已取得名额但还在等待执行延迟、速率限制或者权重容量的任务，以及超时后仍在后台运行的任务
+	Running int
+	// 该分类排队中的任务数，包括任务队列中的任务以及等待该分类并发名额的任务，不包括已取得该分类并发名额的任务
+	Queued int
+	// 该分类的并发数限制，小于等于0表示不限制
+	Limit int
+}
+
+// 按照键限制同时执行的任务数量的调度器，用于实现按键串行执行以及按分类限制并发数
+//
+// 每个键同一时间最多被limit个任务条目持有，worker取出的任务条目所对应的键没有空余名额时，该任务条目会被放入该键的等待列表
+// 持有名额的任务执行完成后，其名额会被转交给等待列表中的第一个任务条目，该任务条目会被放回任务队列
+type keyedLimiter[T any] struct {
+	// 获取任务的键的函数
+	key func(task T) string
+	// 获取每个键最多同时执行的任务数量的函数，返回值小于等于0时表示不限制
+	limit func(key string) int
+	// 每个键已被持有的名额数量
+	running map[string]int
+	// 当前持有名额的全部任务条目
+	holders map[*taskEntry[T]]struct{}
+	// 每个键的等待列表，顺序为任务被取出的先后顺序
+	waiting map[string][]*taskEntry[T]
+	// 锁
+	lock sync.Mutex
+}
+
+// 创建按照键限制同时执行的任务数量的调度器
+//
+//   - key 获取任务的键的函数
+//   - limit 获取每个键最多同时执行的任务数量的函数，返回值小于等于0时表示不限制
+//
+// 返回调度器对象指针
+func newKeyedLimiter[T any](key func(task T) string, limit func(key string) int) *keyedLimiter[T] {
+	return &keyedLimiter[T]{
+		key:     key,
+		limit:   limit,
+		running: make(map[string]int),
+		holders: make(map[*taskEntry[T]]struct{}),
+		waiting: make(map[string][]*taskEntry[T]),
+		lock:    sync.Mutex{},
+	}
+}
+
+// 尝试使任务条目持有其对应的键的一个名额
+//
+//   - entry 任务条目
+//
+// 成功持有或者已经持有名额时返回true，否则该任务条目会被放入等待列表并返回false
+func (limiter *keyedLimiter[T]) acquire(entry *taskEntry[T]) bool {
+	key := limiter.key(entry.task)
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	if _, ok := limiter.holders[entry]; ok {
+		return true
+	}
+	if limit := limiter.limit(key); limit <= 0 || limiter.running[key] < limit {
+		limiter.running[key]++
+		limiter.holders[entry] = struct{}{}
+		return true
+	}
+	limiter.waiting[key] = append(limiter.waiting[key], entry)
+	return false
+}
+
+// 释放任务条目持有的名额，该名额会被转交给等待列表中的第一个任务条目
+//
+//   - entry 执行完成的任务条目
+//
+// 返回接下来持有该名额的任务条目，该任务条目需要被放回任务队列，等待列表为空时返回nil
+func (limiter *keyedLimiter[T]) release(entry *taskEntry[T]) *taskEntry[T] {
+	key := limiter.key(entry.task)
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	if _, ok := limiter.holders[entry]; !ok {
+		return nil
+	}
+	delete(limiter.holders, entry)
+	waitingList := limiter.waiting[key]
+	if len(waitingList) == 0 {
+		limiter.running[key]--
+		if limiter.running[key] == 0 {
+			delete(limiter.running, key)
+		}
+		return nil
+	}
+	next := waitingList[0]
+	if len(waitingList) == 1 {
+		delete(limiter.waiting, key)
+	} else {
+		limiter.waiting[key] = waitingList[1:]
+	}
+	limiter.holders[next] = struct{}{}
+	return next
+}
+
+// 获取每个键已被持有的名额数量，以及当前持有名额的全部任务条目
+//
+// 返回每个键已被持有的名额数量，以及持有名额的任务条目集合，均为副本
+func (limiter *keyedLimiter[T]) holding() (map[string]int, map[*taskEntry[T]]struct{}) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	counts := make(map[string]int, len(limiter.running))
+	for key, count := range limiter.running {
+		counts[key] = count
+	}
+	holders := make(map[*taskEntry[T]]struct{}, len(limiter.holders))
+	for entry := range limiter.holders {
+		holders[entry] = struct{}{}
+	}
+	return counts, holders
+}
+
+// 获取全部等待列表中的任务条目
+//
+// 返回等待中的任务条目，同一个键的任务条目按照等待顺序排列
+func (limiter *keyedLimiter[T]) waitingEntries() []*taskEntry[T] {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	entries := make([]*taskEntry[T], 0)
+	for _, waitingList := range limiter.waiting {
+		entries = append(entries, waitingList...)
+	}
+	return entries
+}
\ No newline at end of file
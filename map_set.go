@@ -102,8 +102,9 @@ func (set *mapSet[T]) toSlice() []T {
 	set.lock.RLock()
 	defer set.lock.RUnlock()
 	slice := make([]T, 0, len(set.data))
-	set.forEach(func(item T) {
+	// 已经持有读锁，直接遍历，避免重复加读锁在有写者等待时发生死锁
+	for item := range set.data {
 		slice = append(slice, item)
-	})
+	}
 	return slice
 }
\ No newline at end of file
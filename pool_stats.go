@@ -0,0 +1,259 @@
+package concurrent_task_pool
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 吞吐量统计保留的最长时间窗口，单位为秒
+const throughputWindowSeconds = 60
+
+// 吞吐量计数桶的数量，除了时间窗口内的完整的秒之外，还需要记录当前还未结束的秒
+const throughputBucketCount = throughputWindowSeconds + 1
+
+// 执行耗时直方图的桶数量，第i个桶记录执行耗时小于 2^i 微秒的执行，最后一个桶记录全部更长的执行
+const latencyBucketCount = 40
+
+// PoolStats 是任务池的运行指标快照，可通过任务池的 Stats 方法获取
+type PoolStats struct {
+	// 已提交的任务数量，包括初始任务列表中的任务，以及通过 Submit 、 Retry 等方法提交的任务，不包括被自动重试的任务
+	Submitted int64
+	// 执行成功的任务数量
+	Succeeded int64
+	// 最终执行失败的任务数量，包括因依赖执行失败而没有执行的任务
+	Failed int64
+	// 执行失败后被自动重试的次数
+	Retried int64
+	// 正在执行的任务数量，即任务执行回调函数正在运行的任务，包括超时后仍在后台运行的任务
+	Running int
+	// 排队中的任务数量，包括已被worker取出，但还在等待执行延迟、速率限制或者权重容量的任务
+	Queued int
+	// 正在等待退避时间结束以重试的任务数量
+	Retrying int
+	// 最近1秒内平均每秒执行完成（执行成功或者最终执行失败）的任务数量，吞吐量按照已经结束的完整的秒统计，不包括当前还未结束的秒
+	Throughput1s float64
+	// 最近10秒内平均每秒执行完成的任务数量
+	Throughput10s float64
+	// 最近1分钟内平均每秒执行完成的任务数量
+	Throughput1m float64
+	// 每次执行任务的耗时统计，任务的每次执行（包括执行失败后的重试）都会被统计
+	Latency LatencyStats
+}
+
+// LatencyStats 是任务执行耗时的统计结果
+// 分位数基于按2的幂划分的直方图估算，其值为对应的桶的上界，且不会超过最大耗时
+type LatencyStats struct {
+	// 统计的执行次数
+	Count int64
+	// 平均执行耗时
+	Mean time.Duration
+	// 最大执行耗时
+	Max time.Duration
+	// 50%分位的执行耗时
+	P50 time.Duration
+	// 90%分位的执行耗时
+	P90 time.Duration
+	// 99%分位的执行耗时
+	P99 time.Duration
+}
+
+// 统计任务执行耗时的直方图，全部字段均通过原子操作读写，可以被多个worker并发记录
+type latencyHistogram struct {
+	// 每个桶记录的执行次数
+	buckets [latencyBucketCount]int64
+	// 全部执行耗时之和，单位为纳秒
+	sum int64
+	// 最大执行耗时，单位为纳秒
+	max int64
+}
+
+// 记录一次执行耗时
+//
+//   - latency 执行耗时
+func (histogram *latencyHistogram) record(latency time.Duration) {
+	index := bits.Len64(uint64(latency / time.Microsecond))
+	if index >= latencyBucketCount {
+		index = latencyBucketCount - 1
+	}
+	atomic.AddInt64(&histogram.buckets[index], 1)
+	atomic.AddInt64(&histogram.sum, int64(latency))
+	for {
+		current := atomic.LoadInt64(&histogram.max)
+		if int64(latency) <= current || atomic.CompareAndSwapInt64(&histogram.max, current, int64(latency)) {
+			return
+		}
+	}
+}
+
+// 获取执行耗时的统计结果
+//
+// 返回执行耗时的统计结果，没有任何执行记录时全部为零值
+func (histogram *latencyHistogram) snapshot() LatencyStats {
+	var buckets [latencyBucketCount]int64
+	var count int64
+	for i := range buckets {
+		buckets[i] = atomic.LoadInt64(&histogram.buckets[i])
+		count += buckets[i]
+	}
+	if count == 0 {
+		return LatencyStats{}
+	}
+	maxLatency := time.Duration(atomic.LoadInt64(&histogram.max))
+	// 找到累计次数达到分位数的桶，使用其上界作为估算值
+	quantile := func(q float64) time.Duration {
+		target := int64(float64(count)*q + 0.5)
+		if target < 1 {
+			target = 1
+		}
+		var cumulative int64
+		for i, bucketCount := range buckets {
+			cumulative += bucketCount
+			if cumulative >= target {
+				upper := time.Duration(1<<uint(i)) * time.Microsecond
+				if i == latencyBucketCount-1 || upper > maxLatency {
+					return maxLatency
+				}
+				return upper
+			}
+		}
+		return maxLatency
+	}
+	return LatencyStats{
+		Count: count,
+		Mean:  time.Duration(atomic.LoadInt64(&histogram.sum) / count),
+		Max:   maxLatency,
+		P50:   quantile(0.5),
+		P90:   quantile(0.9),
+		P99:   quantile(0.99),
+	}
+}
+
+// 按秒统计执行完成的任务数量的滑动窗口计数器
+type throughputCounter struct {
+	// 环形的每秒计数桶，下标为秒数对桶的数量取余
+	counts [throughputBucketCount]int64
+	// 每个桶对应的秒数，用于判断桶是否已经过期
+	seconds [throughputBucketCount]int64
+	// 开始统计的时间，统计时长不足窗口长度时以该时间计算平均值
+	startTime time.Time
+	// 锁
+	lock sync.Mutex
+}
+
+// 重新开始统计
+//
+//   - now 开始统计的时间
+func (counter *throughputCounter) reset(now time.Time) {
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	counter.counts = [throughputBucketCount]int64{}
+	counter.seconds = [throughputBucketCount]int64{}
+	counter.startTime = now
+}
+
+// 记录一个执行完成的任务
+//
+//   - now 任务执行完成的时间
+func (counter *throughputCounter) record(now time.Time) {
+	second := now.Unix()
+	index := second % throughputBucketCount
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	if counter.seconds[index] != second {
+		counter.seconds[index] = second
+		counter.counts[index] = 0
+	}
+	counter.counts[index]++
+}
+
+// 计算最近一段时间内平均每秒执行完成的任务数量
+// 只统计当前秒之前的完整的秒，当前秒的计数还不完整，统计在内会使结果随当前秒的进度周期性地波动
+//
+//   - now 当前时间
+//   - window 时间窗口的长度，单位为秒，不能超过 throughputWindowSeconds
+//
+// 返回平均每秒执行完成的任务数量，统计时长不足窗口长度时以实际的统计时长计算，开始统计还不足1秒时，统计当前秒已完成的任务
+func (counter *throughputCounter) rate(now time.Time, window int64) float64 {
+	second := now.Unix()
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	// 统计的秒数范围为(from, to]，以及统计的时长
+	from, to := second-window-1, second-1
+	elapsed := time.Unix(second, 0).Sub(counter.startTime).Seconds()
+	if counter.startTime.IsZero() || elapsed > float64(window) {
+		elapsed = float64(window)
+	}
+	if elapsed < 1 {
+		to = second
+		elapsed = now.Sub(counter.startTime).Seconds()
+		if elapsed < 1 {
+			elapsed = 1
+		}
+	}
+	var total int64
+	for i := range counter.seconds {
+		if counter.seconds[i] > from && counter.seconds[i] <= to {
+			total += counter.counts[i]
+		}
+	}
+	return float64(total) / elapsed
+}
+
+// 任务池运行指标的记录器，由worker在任务状态变化时更新
+type poolStats struct {
+	// 已提交的任务数量
+	submitted int64
+	// 执行成功的任务数量
+	succeeded int64
+	// 最终执行失败的任务数量
+	failed int64
+	// 被自动重试的次数
+	retried int64
+	// 每次执行任务的耗时直方图
+	latency latencyHistogram
+	// 执行完成的任务的吞吐量计数器
+	throughput throughputCounter
+}
+
+// 创建任务池运行指标的记录器
+//
+//   - submitted 初始的已提交任务数量
+//
+// 返回运行指标记录器对象指针
+func newPoolStats(submitted int) *poolStats {
+	return &poolStats{
+		submitted:  int64(submitted),
+		succeeded:  0,
+		failed:     0,
+		retried:    0,
+		latency:    latencyHistogram{},
+		throughput: throughputCounter{},
+	}
+}
+
+// 记录一次任务执行的结果
+//
+//   - duration 本次执行的耗时
+//   - succeeded 是否执行成功
+//   - retried 执行失败时是否会被重试
+func (stats *poolStats) recordExecution(duration time.Duration, succeeded, retried bool) {
+	stats.latency.record(duration)
+	switch {
+	case succeeded:
+		atomic.AddInt64(&stats.succeeded, 1)
+	case retried:
+		atomic.AddInt64(&stats.retried, 1)
+		return
+	default:
+		atomic.AddInt64(&stats.failed, 1)
+	}
+	stats.throughput.record(time.Now())
+}
+
+// 记录一个没有被执行就失败的任务，例如因依赖执行失败而不会被执行的任务
+func (stats *poolStats) recordSkipped() {
+	atomic.AddInt64(&stats.failed, 1)
+	stats.throughput.record(time.Now())
+}
\ No newline at end of file
@@ -0,0 +1,139 @@
+package concurrent_task_pool
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// PriorityTask 是可以自定义优先级的任务
+// 启用优先级队列且没有指定优先级函数时，若任务对象实现了该接口，则会使用 Priority 方法的返回值作为任务的优先级
+type PriorityTask interface {
+	// Priority 返回该任务的优先级，值越大越先被执行
+	Priority() int
+}
+
+// 优先级队列中的一个元素
+type priorityItem[T any] struct {
+	// 任务条目
+	entry *taskEntry[T]
+	// 入队序号，优先级相同时，入队序号小的元素先出队
+	sequence int64
+}
+
+// 基于二叉堆的优先级元素切片，实现了 heap.Interface
+type priorityHeap[T any] []*priorityItem[T]
+
+func (h priorityHeap[T]) Len() int {
+	return len(h)
+}
+
+func (h priorityHeap[T]) Less(i, j int) bool {
+	if h[i].entry.priority != h[j].entry.priority {
+		return h[i].entry.priority > h[j].entry.priority
+	}
+	return h[i].sequence < h[j].sequence
+}
+
+func (h priorityHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *priorityHeap[T]) Push(item any) {
+	*h = append(*h, item.(*priorityItem[T]))
+}
+
+func (h *priorityHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// priorityQueue 是一个基于二叉堆的任务优先级队列
+//
+// 优先级越大的任务越先出队，优先级相同的任务按照入队的先后顺序出队
+type priorityQueue[T any] struct {
+	// 队列数据
+	data priorityHeap[T]
+	// 下一个入队元素的入队序号
+	sequence int64
+	// 锁
+	lock sync.RWMutex
+	// 用于阻塞等待元素入队的条件变量，基于写锁
+	cond *sync.Cond
+}
+
+// 从一个现有的任务条目切片创建优先级队列
+//
+//   - entries 任务条目切片，优先级相同的任务条目按照切片顺序出队
+//
+// 返回包含了全部任务条目的优先级队列
+func newPriorityQueue[T any](entries []*taskEntry[T]) *priorityQueue[T] {
+	queue := &priorityQueue[T]{
+		data:     make(priorityHeap[T], 0, len(entries)),
+		sequence: 0,
+		lock:     sync.RWMutex{},
+	}
+	queue.cond = sync.NewCond(&queue.lock)
+	for _, entry := range entries {
+		queue.data = append(queue.data, &priorityItem[T]{entry: entry, sequence: queue.sequence})
+		queue.sequence++
+	}
+	heap.Init(&queue.data)
+	return queue
+}
+
+// 任务条目入队
+//
+//   - entry 入队的任务条目
+func (queue *priorityQueue[T]) offer(entry *taskEntry[T]) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	heap.Push(&queue.data, &priorityItem[T]{entry: entry, sequence: queue.sequence})
+	queue.sequence++
+	// 唤醒一个正在等待取出元素的线程
+	queue.cond.Signal()
+}
+
+// 阻塞地取出优先级最高的任务条目
+// 若队列为空，则会一直等待直到有元素入队，或者stop返回true
+//
+//   - stop 判断是否停止等待的函数，该函数会在持有队列锁时被调用
+//
+// 返回优先级最高的任务条目，以及是否成功取出了元素，stop返回true时不会取出元素
+func (queue *priorityQueue[T]) take(stop func() bool) (*taskEntry[T], bool) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	for len(queue.data) == 0 && !stop() {
+		queue.cond.Wait()
+	}
+	if stop() {
+		return nil, false
+	}
+	return heap.Pop(&queue.data).(*priorityItem[T]).entry, true
+}
+
+// 唤醒全部正在 take 中等待的线程，使其重新检查停止条件
+func (queue *priorityQueue[T]) wakeAll() {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	queue.cond.Broadcast()
+}
+
+// 队列转换成切片
+//
+// 返回存放队列全部任务条目的切片，顺序为出队的先后顺序
+func (queue *priorityQueue[T]) toSlice() []*taskEntry[T] {
+	queue.lock.RLock()
+	items := make(priorityHeap[T], len(queue.data))
+	copy(items, queue.data)
+	queue.lock.RUnlock()
+	sort.Slice(items, items.Less)
+	entries := make([]*taskEntry[T], 0, len(items))
+	for _, item := range items {
+		entries = append(entries, item.entry)
+	}
+	return entries
+}
\ No newline at end of file
@@ -0,0 +1,148 @@
+package concurrent_task_pool
+
+import (
+	"sync"
+	"time"
+)
+
+// 清理空闲令牌桶的时间间隔
+const idleBucketSweepInterval = time.Minute
+
+// 令牌桶，以固定的速率生成令牌，并允许一定数量的突发
+type tokenBucket struct {
+	// 每秒生成的令牌数
+	rate float64
+	// 令牌桶的容量，即允许的最大突发数量
+	burst float64
+	// 当前的令牌数，为负数时表示已被预订的令牌数
+	tokens float64
+	// 上一次更新令牌数的时间
+	last time.Time
+}
+
+// 创建一个装满令牌的令牌桶
+//
+//   - rate 每秒生成的令牌数
+//   - burst 令牌桶的容量
+//
+// 返回令牌桶对象指针
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// 补充截止到指定时间生成的令牌
+//
+//   - now 当前时间
+func (bucket *tokenBucket) refill(now time.Time) {
+	bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.rate
+	if bucket.tokens > bucket.burst {
+		bucket.tokens = bucket.burst
+	}
+	bucket.last = now
+}
+
+// 判断令牌桶是否已经装满，装满的令牌桶与新创建的令牌桶等价，可以被清理
+//
+//   - now 当前时间
+//
+// 装满时返回true
+func (bucket *tokenBucket) isFull(now time.Time) bool {
+	bucket.refill(now)
+	return bucket.tokens >= bucket.burst
+}
+
+// 预订一个令牌
+//
+//   - now 当前时间
+//
+// 返回需要等待多长时间才能使用该令牌，为0时表示可以立即使用
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+	bucket.refill(now)
+	// 取出令牌，令牌不足时预订之后生成的令牌
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+// 任务池的速率限制器，被全部worker共享，可以按照任务的键分别限制速率
+type rateLimiter[T any] struct {
+	// 每个令牌桶每秒生成的令牌数
+	rate float64
+	// 每个令牌桶的容量
+	burst int
+	// 获取任务的键的函数，键相同的任务共享一个令牌桶，为nil时全部任务共享一个令牌桶
+	key func(task T) string
+	// 每个键对应的令牌桶，已经装满的令牌桶会被定期清理
+	buckets map[string]*tokenBucket
+	// 上一次清理空闲令牌桶的时间
+	lastSweep time.Time
+	// 保护buckets以及全部令牌桶的锁
+	lock sync.Mutex
+}
+
+// 创建速率限制器
+//
+//   - rate 每秒允许执行的任务数
+//   - burst 允许突发执行的任务数，小于1时会被设为1
+//   - key 获取任务的键的函数，可以为nil
+//
+// 返回速率限制器对象指针
+func newRateLimiter[T any](rate float64, burst int, key func(task T) string) *rateLimiter[T] {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter[T]{
+		rate:      rate,
+		burst:     burst,
+		key:       key,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		lock:      sync.Mutex{},
+	}
+}
+
+// 为即将执行的任务预订一个令牌
+// 令牌不足时会预订之后生成的令牌，任务需要等待返回的时长之后才能执行，期间worker可以执行其它任务
+//
+//   - task 即将执行的任务对象
+//
+// 返回需要等待多长时间才能执行该任务，为0时表示可以立即执行
+func (limiter *rateLimiter[T]) reserve(task T) time.Duration {
+	key := ""
+	if limiter.key != nil {
+		key = limiter.key(task)
+	}
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	now := time.Now()
+	limiter.sweep(now)
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limiter.rate, limiter.burst)
+		limiter.buckets[key] = bucket
+	}
+	return bucket.reserve(now)
+}
+
+// 定期清理已经装满的令牌桶，避免键很多时令牌桶一直增长，需要在持有锁时调用
+// 装满的令牌桶与之后重新创建的令牌桶等价，因此清理不会影响速率限制
+//
+//   - now 当前时间
+func (limiter *rateLimiter[T]) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < idleBucketSweepInterval {
+		return
+	}
+	limiter.lastSweep = now
+	for key, bucket := range limiter.buckets {
+		if bucket.isFull(now) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,103 @@
+package concurrent_task_pool
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 任务的重试策略
+// 为任务池设定重试策略后，当任务执行回调函数返回错误时，任务池会按照该策略自动地将任务重新放回任务队列进行重试
+// 超过最大执行次数或者错误不可重试时，任务会被记录为失败，并放入死信任务列表
+type RetryPolicy struct {
+	// 每个任务的最大执行次数（包括第一次执行），小于等于0表示不限制次数
+	MaxAttempts int
+	// 第一次重试之前的退避时间，为0表示立即重试
+	Backoff time.Duration
+	// 退避时间的最大值，为0表示不限制
+	MaxBackoff time.Duration
+	// 每次重试后退避时间增长的倍数，小于等于1时为固定退避时间，否则为指数退避
+	Multiplier float64
+	// 退避时间的随机抖动比例，取值范围为0-1
+	// 例如设为0.2时，实际退避时间会在计算所得的退避时间的80%-120%之间随机取值
+	Jitter float64
+	// 判断一个错误是否可以重试的自定义回调函数，可以为nil，此时全部错误都可重试
+	//
+	// 回调函数参数：
+	//  - e 任务执行时返回的错误
+	//
+	// 返回值：该错误是否可以重试
+	Retryable func(e error) bool
+}
+
+// NewFixedRetryPolicy 创建一个固定退避时间的重试策略
+//
+//   - maxAttempts 每个任务的最大执行次数（包括第一次执行），小于等于0表示不限制次数
+//   - backoff 每次重试之前的退避时间
+//
+// 返回重试策略对象指针
+func NewFixedRetryPolicy(maxAttempts int, backoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+		Multiplier:  1,
+	}
+}
+
+// NewExponentialRetryPolicy 创建一个指数退避的重试策略，每次重试后退避时间翻倍，并带有20%的随机抖动
+//
+//   - maxAttempts 每个任务的最大执行次数（包括第一次执行），小于等于0表示不限制次数
+//   - backoff 第一次重试之前的退避时间
+//   - maxBackoff 退避时间的最大值，为0表示不限制
+//
+// 返回重试策略对象指针
+func NewExponentialRetryPolicy(maxAttempts int, backoff, maxBackoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+		MaxBackoff:  maxBackoff,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// 判断一个执行失败的任务是否应当被重试
+//
+//   - attempts 该任务已经被执行的次数
+//   - e 任务执行时返回的错误
+//
+// 返回是否应当重试
+func (policy *RetryPolicy) shouldRetry(attempts int, e error) bool {
+	if policy.MaxAttempts > 0 && attempts >= policy.MaxAttempts {
+		return false
+	}
+	return policy.Retryable == nil || policy.Retryable(e)
+}
+
+// 计算下一次重试之前的退避时间
+//
+//   - attempts 该任务已经被执行的次数
+//
+// 返回退避时间
+func (policy *RetryPolicy) backoff(attempts int) time.Duration {
+	delay := float64(policy.Backoff)
+	if delay <= 0 {
+		return 0
+	}
+	// 指数退避
+	if policy.Multiplier > 1 {
+		for i := 1; i < attempts; i++ {
+			delay *= policy.Multiplier
+			if policy.MaxBackoff > 0 && delay >= float64(policy.MaxBackoff) {
+				break
+			}
+		}
+	}
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+	// 随机抖动
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(delay)
+}
\ No newline at end of file
@@ -1,34 +1,39 @@
 package concurrent_task_pool
 
 import (
+	"context"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 )
 
 // ReturnableTaskPool 并发任务池，用于执行指定数量的并发多任务，其中任务是无返回值的
-type ReturnableTaskPool[T, R comparable] struct {
+type ReturnableTaskPool[T, R any] struct {
 	basePool[T]
 	// 执行每个任务的回调函数逻辑
 	//
 	// 回调函数参数：
+	//  - ctx 任务池运行时的上下文，当任务池被中断、接收到终止信号或者父上下文被取消时，该上下文会被取消
 	//  - task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
 	//  - taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
 	//
-	// 返回值：任务执行完成后的返回结果
-	run func(task T, taskPool *ReturnableTaskPool[T, R]) R
+	// 返回值：任务执行完成后的返回结果，以及任务执行失败时返回的错误
+	run func(ctx context.Context, task T, taskPool *ReturnableTaskPool[T, R]) (R, error)
 	// 接收到终止信号后的操作
 	//
 	// 参数为当前并发任务池对象，可从其中获取任务状态并执行保存
 	shutdown func(taskPool *ReturnableTaskPool[T, R])
 	// 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil
-	// 该回调函数会在任务池执行任务时被不间断调用
+	// 该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 	// 任务池全部任务执行完成后，该回调函数不会再被调用
 	//
 	// 参数为当前并发任务池对象，可从中实时读取任务池状态
 	lookup func(pool *ReturnableTaskPool[T, R])
+	// 全部任务的执行结果，按照任务完成的先后顺序存放
+	results *arrayQueue[*TaskResult[T, R]]
+	// 流式模式下，用于实时发送每个任务执行结果的通道，为nil时结果会被收集到results中
+	resultChannel chan *TaskResult[T, R]
 }
 
 // NewReturnableTaskPool 通过现有的任务列表创建任务池
@@ -48,26 +53,83 @@ type ReturnableTaskPool[T, R comparable] struct {
 //   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
 //     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
 //   - lookup 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil
-//     该回调函数会在任务池执行任务时被不间断调用
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 //     任务池全部任务执行完成后，该回调函数不会再被调用
 //     其参数为：
 //     taskPool 当前并发任务池对象，可从中实时读取任务池状态
 //
 // 返回一个新建的有返回值的并发任务池对象指针
-func NewReturnableTaskPool[T, R comparable](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R, shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
+func NewReturnableTaskPool[T, R any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R, shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
+	return NewContextReturnableTaskPool[T, R](concurrent, createInterval, executeDelay, taskList, func(ctx context.Context, task T, taskPool *ReturnableTaskPool[T, R]) R {
+		return runFunction(task, taskPool)
+	}, shutdownFunction, lookupFunction)
+}
+
+// NewContextReturnableTaskPool 通过现有的任务列表创建有返回值的任务池，其任务执行回调函数能够接收任务池的上下文
+// 当任务池被中断、接收到终止信号或者启动任务池时传入的父上下文被取消时，该上下文会被取消，可用于及时终止正在执行的网络请求等操作
+//
+//   - concurrent 任务并发数，即worker数量，每一个worker负责在一个单独的线程中运行任务，当队列中任务数量足够时，并发任务池会一直保持有concurrent个任务一直在并发运行
+//   - createInterval 创建worker时的时间间隔
+//     若设为0则会在开启并发任务池时同时创建完成全部worker
+//     该参数不影响任务池执行时worker从队列取出任务的速度，仅仅代表任务池初始化时创建worker的间隔
+//   - executeDelay worker执行每个任务之前的延迟
+//     若设为0则所有worker每次从任务队列取出任务后就立即执行
+//     否则，当worker每次从任务队列取出任务时，会延迟一段时间再执行任务
+//   - taskList 存放全部任务的切片
+//   - runFunction 自定义执行任务逻辑的回调函数，其参数为：
+//     ctx 任务池运行时的上下文，任务池被中断时会被取消
+//     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
+//     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
+//     返回值：每个任务执行完成后的返回结果
+//   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
+//     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
+//   - lookup 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
+//     任务池全部任务执行完成后，该回调函数不会再被调用
+//     其参数为：
+//     taskPool 当前并发任务池对象，可从中实时读取任务池状态
+//
+// 返回一个新建的有返回值的并发任务池对象指针
+func NewContextReturnableTaskPool[T, R any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(ctx context.Context, task T, taskPool *ReturnableTaskPool[T, R]) R, shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
+	return NewErrorReturnableTaskPool[T, R](concurrent, createInterval, executeDelay, taskList, func(ctx context.Context, task T, taskPool *ReturnableTaskPool[T, R]) (R, error) {
+		return runFunction(ctx, task, taskPool), nil
+	}, shutdownFunction, lookupFunction)
+}
+
+// NewErrorReturnableTaskPool 通过现有的任务列表创建有返回值的任务池，其任务执行回调函数能够接收任务池的上下文，并返回任务执行的错误
+// 任务返回的错误会被任务池记录，可通过 GetFailedTaskList 获取失败的任务，执行失败的任务的返回值不会被收集，任务池执行完成后， Start 方法会返回聚合了全部失败任务的错误
+//
+//   - concurrent 任务并发数，即worker数量，每一个worker负责在一个单独的线程中运行任务，当队列中任务数量足够时，并发任务池会一直保持有concurrent个任务一直在并发运行
+//   - createInterval 创建worker时的时间间隔
+//     若设为0则会在开启并发任务池时同时创建完成全部worker
+//     该参数不影响任务池执行时worker从队列取出任务的速度，仅仅代表任务池初始化时创建worker的间隔
+//   - executeDelay worker执行每个任务之前的延迟
+//     若设为0则所有worker每次从任务队列取出任务后就立即执行
+//     否则，当worker每次从任务队列取出任务时，会延迟一段时间再执行任务
+//   - taskList 存放全部任务的切片
+//   - runFunction 自定义执行任务逻辑的回调函数，其参数为：
+//     ctx 任务池运行时的上下文，任务池被中断时会被取消
+//     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
+//     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
+//     返回值：每个任务执行完成后的返回结果，以及任务执行失败时返回的错误
+//   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
+//     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
+//   - lookup 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
+//     任务池全部任务执行完成后，该回调函数不会再被调用
+//     其参数为：
+//     taskPool 当前并发任务池对象，可从中实时读取任务池状态
+//
+// 返回一个新建的有返回值的并发任务池对象指针
+func NewErrorReturnableTaskPool[T, R any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(ctx context.Context, task T, taskPool *ReturnableTaskPool[T, R]) (R, error), shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
 	return &ReturnableTaskPool[T, R]{
-		basePool: basePool[T]{
-			concurrent:         concurrent,
-			taskCreateInterval: createInterval,
-			workerExecuteDelay: executeDelay,
-			taskQueue:          newArrayQueueFromSlice(taskList),
-			runningTasks:       newMapSet[T](),
-			isInterrupt:        false,
-			isAutoSaving:       false,
-		},
+		basePool: newBasePool(concurrent, createInterval, executeDelay, taskList),
 		run:      runFunction,
 		shutdown: shutdownFunction,
 		lookup:   lookupFunction,
+		results:  newArrayQueue[*TaskResult[T, R]](),
+		// 默认不使用流式模式
+		resultChannel: nil,
 	}
 }
 
@@ -84,7 +146,7 @@ func NewReturnableTaskPool[T, R comparable](concurrent int, createInterval, exec
 //     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
 //     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
 //     返回值：每个任务执行完成后的返回结果
-func NewSimpleReturnableTaskPool[T, R comparable](concurrent int, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R) *ReturnableTaskPool[T, R] {
+func NewSimpleReturnableTaskPool[T, R any](concurrent int, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R) *ReturnableTaskPool[T, R] {
 	return NewReturnableTaskPool[T, R](concurrent, 0, 0, taskList, runFunction, nil, nil)
 }
 
@@ -102,11 +164,11 @@ func NewSimpleReturnableTaskPool[T, R comparable](concurrent int, taskList []T,
 //   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
 //     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
 //   - lookupFunction 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil，
-//     该回调函数会在任务池执行任务时被不间断调用
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 //     任务池全部任务执行完成后，该回调函数不会再被调用
 //     其参数为：
 //     taskPool 并发任务池本身，可从中实时读取任务池状态
-func NewNoDelayReturnableTaskPool[T, R comparable](concurrent int, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R, shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
+func NewNoDelayReturnableTaskPool[T, R any](concurrent int, taskList []T, runFunction func(task T, taskPool *ReturnableTaskPool[T, R]) R, shutdownFunction func(taskPool *ReturnableTaskPool[T, R]), lookupFunction func(taskPool *ReturnableTaskPool[T, R])) *ReturnableTaskPool[T, R] {
 	return NewReturnableTaskPool[T, R](concurrent, 0, 0, taskList, runFunction, shutdownFunction, lookupFunction)
 }
 
@@ -114,12 +176,30 @@ func NewNoDelayReturnableTaskPool[T, R comparable](concurrent int, taskList []T,
 //
 //   - ignoreEmpty 是否收集空的任务执行返回值
 //
-// 返回全部任务执行后的返回值列表
-func (pool *ReturnableTaskPool[T, R]) Start(ignoreEmpty bool) []R {
-	// 结果收集锁
-	lock := &sync.Mutex{}
-	// 用于控制worker运行的变量，当为false时全部worker将一直等待从任务取出任务执行，否则都会立即停止运行
-	workerShutdown := false
+// 返回全部任务执行后的返回值列表，以及聚合了全部失败任务的错误 *PoolError ，若不存在失败的任务则错误为nil
+func (pool *ReturnableTaskPool[T, R]) Start(ignoreEmpty bool) ([]R, error) {
+	return pool.StartContext(context.Background(), ignoreEmpty)
+}
+
+// StartContext 使用给定的父上下文启动并发任务池
+// 当父上下文被取消时，任务池会被中断，且传递给任务执行回调函数的上下文也会被取消
+//
+//   - ctx 父上下文
+//   - ignoreEmpty 是否收集空的任务执行返回值
+//
+// 返回全部任务执行后的返回值列表，若任务池被中断，则只包含中断前已完成任务的返回值
+// 以及聚合了全部失败任务的错误 *PoolError ，若不存在失败的任务则错误为nil
+// 启用依赖关系时，若依赖关系无效（例如存在循环依赖），则任务池不会启动，并返回对应的错误
+func (pool *ReturnableTaskPool[T, R]) StartContext(ctx context.Context, ignoreEmpty bool) ([]R, error) {
+	// 校验任务的依赖关系
+	if e := pool.validateDependencies(); e != nil {
+		return nil, e
+	}
+	// 初始化任务池上下文
+	pool.initContext(ctx)
+	defer pool.cancelContext()
+	// 通知监听器初始任务列表中的任务入队
+	pool.notifyInitialQueued()
 	// 在一个新的线程接收终止信号
 	var signals chan os.Signal
 	if pool.shutdown != nil {
@@ -129,39 +209,123 @@ func (pool *ReturnableTaskPool[T, R]) Start(ignoreEmpty bool) []R {
 			// 等待信号
 			s := <-signals
 			if s != nil {
-				// 结束全部worker
-				workerShutdown = true
-				// 执行shutdown回调
-				pool.shutdown(pool)
-				// 标记为中断
-				pool.isInterrupt = true
+				pool.handleSignal(func() {
+					pool.shutdown(pool)
+				})
 			}
 		}()
 	}
-	// 创建结果列表切片
-	resultList := make([]R, 0)
-	// 创建worker
-	for i := 0; i < pool.concurrent; i++ {
-		eachWorker := newReturnableWorker[T, R](pool.run, &resultList, pool)
-		eachWorker.start(lock, &workerShutdown, ignoreEmpty)
-		if pool.taskCreateInterval > 0 {
-			time.Sleep(pool.taskCreateInterval)
-		}
-	}
-	// 等待直到队列中无任务，且任务列表中也没有任务了，说明全部任务完成
-	// 若被标记为中断，则会立即结束
-	for !pool.isInterrupt && !pool.IsAllDone() {
-		// 执行lookup函数
-		if pool.lookup != nil {
+	// 按照任务并发数创建worker，任务池运行期间修改任务并发数时也会通过该函数创建新的worker
+	pool.startWorkers(func(id int) {
+		newReturnableWorker[T, R](id, pool.run, pool).start()
+	})
+	// 阻塞等待直到任务池全部任务完成，期间定时执行lookup函数
+	// 如果被标记为中断，或者上下文被取消，则会立即退出
+	var lookup func()
+	if pool.lookup != nil {
+		lookup = func() {
 			pool.lookup(pool)
 		}
 	}
-	// 结束全部worker
-	workerShutdown = true
+	pool.waitDone(lookup)
+	// 结束全部worker，并不再接收新的任务
+	pool.shutdownWorkers()
+	pool.isClosed.set(true)
 	// 关闭信号接收通道
 	if signals != nil {
 		signal.Stop(signals)
 		close(signals)
 	}
+	// 通知监听器任务池运行结束
+	e := pool.aggregateError()
+	pool.notifyDone(e)
+	return pool.collectValues(ignoreEmpty), e
+}
+
+// 收集一个任务的执行结果
+// 流式模式下结果会被发送到结果通道，通道已满时会阻塞直到结果被读取或者任务池被中断，否则结果会被存放至结果列表
+//
+//   - result 任务的执行结果
+func (pool *ReturnableTaskPool[T, R]) collectResult(result *TaskResult[T, R]) {
+	if pool.resultChannel == nil {
+		pool.results.offer(result)
+		return
+	}
+	select {
+	case pool.resultChannel <- result:
+	case <-pool.ctx.Done():
+	}
+}
+
+// 从全部任务的执行结果中收集返回值，执行失败的任务不会被收集
+//
+//   - ignoreEmpty 是否忽略零值的返回值
+//
+// 返回值列表，按照任务完成的先后顺序排列
+func (pool *ReturnableTaskPool[T, R]) collectValues(ignoreEmpty bool) []R {
+	results := pool.results.toSlice()
+	resultList := make([]R, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil || (ignoreEmpty && isZeroValue(result.Value)) {
+			continue
+		}
+		resultList = append(resultList, result.Value)
+	}
 	return resultList
+}
+
+// GetResultList 获取全部任务的执行结果，每个结果关联了产生该结果的任务对象、返回值、错误、执行次数以及耗时
+// 执行失败且不再重试的任务同样会包含在其中，其 Err 字段不为nil
+// 可以在任务池执行期间调用，此时返回已完成的任务的结果
+//
+//   - ordered 是否按照任务的提交顺序（即初始任务列表中的顺序）排列结果，为false时按照任务完成的先后顺序排列
+//
+// 返回全部任务执行结果
+func (pool *ReturnableTaskPool[T, R]) GetResultList(ordered bool) []*TaskResult[T, R] {
+	results := pool.results.toSlice()
+	if ordered {
+		sortResultsByIndex(results)
+	}
+	return results
+}
+
+// StartAsync 在一个新的线程中启动并发任务池，不会阻塞当前线程
+//
+//   - ignoreEmpty 是否收集空的任务执行返回值
+//
+// 返回任务池句柄，可通过句柄等待任务池结束、取消任务池或者获取任务的返回值
+func (pool *ReturnableTaskPool[T, R]) StartAsync(ignoreEmpty bool) *ReturnableTaskHandle[R] {
+	ctx, cancel := context.WithCancel(context.Background())
+	handle := &ReturnableTaskHandle[R]{
+		TaskHandle: newTaskHandle(cancel),
+		results:    nil,
+	}
+	go func() {
+		results, e := pool.StartContext(ctx, ignoreEmpty)
+		handle.results = results
+		handle.finish(e)
+	}()
+	return handle
+}
+
+// StartStream 以流式模式在一个新的线程中启动并发任务池，不会阻塞当前线程
+// 流式模式下，每个任务的执行结果会在任务完成后被立即发送到返回的通道中，而不会被收集到任务池的结果列表中，适用于任务数量非常多或者需要实时处理结果的场景
+// 若通道的缓冲区已满，worker会阻塞直到结果被读取，以此实现背压，因此需要持续地从通道中读取结果
+// 任务池结束且全部worker退出后，通道会被关闭，若存在超时后仍在后台运行的任务执行回调函数，则还会等待其返回
+//
+//   - bufferSize 结果通道的缓冲区大小，为0时为无缓冲通道
+//
+// 返回接收任务执行结果的通道，以及任务池句柄，可通过句柄等待任务池结束、取消任务池或者获取任务池执行的错误
+func (pool *ReturnableTaskPool[T, R]) StartStream(bufferSize int) (<-chan *TaskResult[T, R], *TaskHandle) {
+	pool.resultChannel = make(chan *TaskResult[T, R], bufferSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	handle := newTaskHandle(cancel)
+	go func() {
+		_, e := pool.StartContext(ctx, false)
+		// 等待全部worker以及超时任务的后台线程退出后再关闭通道，确保不会再有结果被发送
+		pool.workerGroup.Wait()
+		close(pool.resultChannel)
+		handle.finish(e)
+	}()
+	return pool.resultChannel, handle
 }
\ No newline at end of file
@@ -1,7 +1,11 @@
 package concurrent_task_pool
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -32,7 +36,10 @@ func TestReturnableTaskPool_Start(t *testing.T) {
 			}
 		}, nil)
 	// 3.启动任务池
-	resultList := pool.Start(true)
+	resultList, e := pool.Start(true)
+	if e != nil {
+		t.Error(e)
+	}
 	// 4.执行完成，读取结果
 	fmt.Println("执行完成！全部结果：")
 	for _, result := range resultList {
@@ -73,7 +80,10 @@ func TestReturnableTaskPool_Retry(t *testing.T) {
 			}
 		}, nil)
 	// 3.启动任务池
-	resultList := pool.Start(true)
+	resultList, e := pool.Start(true)
+	if e != nil {
+		t.Error(e)
+	}
 	// 4.执行完成，读取结果
 	fmt.Println("执行完成！全部结果：")
 	for _, result := range resultList {
@@ -111,10 +121,295 @@ func TestReturnableTaskPool_Interrupt(t *testing.T) {
 			}
 		}, nil)
 	// 3.启动任务池
-	resultList := pool.Start(true)
+	resultList, e := pool.Start(true)
+	if e != nil {
+		t.Error(e)
+	}
 	// 4.执行完成，读取结果
 	fmt.Println("执行完成！全部结果：")
 	for _, result := range resultList {
 		fmt.Println(result)
 	}
+}
+
+// 测试有返回值的并发任务池-通过上下文取消任务
+func TestReturnableTaskPool_StartContext(t *testing.T) {
+	// 1.创建任务列表
+	list := createTaskList()
+	// 2.创建任务池，任务执行回调函数可接收上下文
+	pool := NewContextReturnableTaskPool[*DownloadTask, string](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			// 模拟执行任务，上下文被取消时立即结束
+			select {
+			case <-ctx.Done():
+				return ""
+			case <-time.After(200 * time.Millisecond):
+				return task.Filename
+			}
+		}, nil, nil)
+	// 3.使用带超时的上下文启动任务池
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	resultList, e := pool.StartContext(ctx, true)
+	if e != nil {
+		t.Error(e)
+	}
+	// 4.读取中断前完成的结果
+	fmt.Println("已完成的任务结果：")
+	for _, result := range resultList {
+		fmt.Println(result)
+	}
+	if len(resultList) >= len(list) {
+		t.Error("上下文超时后不应当完成全部任务！")
+	}
+}
+
+// 测试有返回值的并发任务池-任务返回错误
+func TestReturnableTaskPool_Error(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池，任务执行回调函数可返回错误
+	pool := NewErrorReturnableTaskPool[*DownloadTask, string](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) (string, error) {
+			// 模拟出现错误
+			if task.Url == "" {
+				return "", fmt.Errorf("文件%s的下载地址为空", task.Filename)
+			}
+			time.Sleep(100 * time.Millisecond)
+			return task.Filename, nil
+		}, nil, nil)
+	// 3.启动任务池
+	resultList, e := pool.Start(true)
+	// 4.读取结果和错误
+	fmt.Printf("成功的任务数：%d\n", len(resultList))
+	fmt.Println(e)
+	if e == nil || len(resultList) != len(list)-1 {
+		t.Error("应当有一个任务执行失败！")
+	}
+}
+
+// 测试有返回值的并发任务池-任务执行时发生panic后重试
+func TestReturnableTaskPool_Panic(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池
+	pool := NewReturnableTaskPool[*DownloadTask, string](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			// 模拟第一次执行时发生panic
+			if task.Url == "" {
+				task.Url = fmt.Sprintf("http://example.com/file/%s", task.Filename)
+				panic("下载地址为空")
+			}
+			time.Sleep(100 * time.Millisecond)
+			return task.Filename
+		}, nil, nil)
+	// 3.发生panic的任务同样适用重试策略
+	pool.SetRetryPolicy(NewFixedRetryPolicy(2, 0))
+	// 4.启动任务池
+	resultList, e := pool.Start(true)
+	if e != nil || len(resultList) != len(list) {
+		t.Error("发生panic的任务应当在重试后成功！", e)
+	}
+}
+
+// 并发压力测试：大量短任务并发收集结果，并在运行期间重试任务，需使用 go test -race 运行以检查数据竞争
+func TestReturnableTaskPool_Stress(t *testing.T) {
+	// 1.创建大量任务
+	list := make([]int, 0)
+	for i := 1; i <= 5000; i++ {
+		list = append(list, i)
+	}
+	// 2.创建任务池
+	pool := NewReturnableTaskPool[int, int](16, 0, 0, list,
+		func(task int, pool *ReturnableTaskPool[int, int]) int {
+			// 重试一部分任务，负数的任务表示重试后的任务
+			if task > 0 && task%100 == 0 {
+				pool.Retry(-task)
+				return 0
+			}
+			return task
+		}, nil,
+		func(pool *ReturnableTaskPool[int, int]) {
+			_ = pool.GetAllTaskList()
+			_ = pool.GetRunningTaskList()
+		})
+	pool.SetLookupInterval(time.Millisecond)
+	// 3.启动任务池，忽略空的结果
+	resultList, e := pool.Start(true)
+	if e != nil || len(resultList) != len(list) {
+		t.Errorf("结果数：%d，错误：%v", len(resultList), e)
+	}
+}
+
+// 测试异步启动有返回值的并发任务池
+func TestReturnableTaskPool_StartAsync(t *testing.T) {
+	// 1.创建任务列表
+	list := createTaskList()
+	// 2.创建任务池
+	pool := NewSimpleReturnableTaskPool[*DownloadTask, string](3, list,
+		func(task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			time.Sleep(50 * time.Millisecond)
+			return task.Filename
+		})
+	// 3.异步启动任务池
+	handle := pool.StartAsync(true)
+	fmt.Println("任务池已在后台启动！")
+	// 4.等待任务池结束并读取结果
+	if e := handle.Wait(); e != nil {
+		t.Error(e)
+	}
+	if len(handle.Results()) != len(list) {
+		t.Error("应当收集到全部任务的返回值！")
+	}
+}
+
+// 测试有返回值的并发任务池-获取关联了任务对象的执行结果
+func TestReturnableTaskPool_GetResultList(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池
+	pool := NewErrorReturnableTaskPool[*DownloadTask, int](3, 0, 0, list,
+		func(ctx context.Context, task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, int]) (int, error) {
+			if task.Url == "" {
+				return 0, fmt.Errorf("文件%s的下载地址为空", task.Filename)
+			}
+			// 模拟不同任务的耗时不同，使任务完成的顺序与提交顺序不一致
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+			return len(task.Url), nil
+		}, nil, nil)
+	// 3.启动任务池
+	_, _ = pool.Start(false)
+	// 4.按照提交顺序获取结果，并与任务列表对应
+	results := pool.GetResultList(true)
+	for i, result := range results {
+		fmt.Printf("任务：%s，返回值：%d，错误：%v，执行次数：%d，耗时：%s\n", result.Task.Filename, result.Value, result.Err, result.Attempts, result.Duration)
+		if result.Task != list[i] || result.Index != i {
+			t.Error("结果的顺序应当与任务列表一致！")
+		}
+	}
+	if len(results) != len(list) || results[2].Err == nil {
+		t.Error("应当包含全部任务的结果，其中第3个任务失败！")
+	}
+}
+
+// 测试有返回值的并发任务池-流式获取任务执行结果
+func TestReturnableTaskPool_StartStream(t *testing.T) {
+	// 1.创建任务列表
+	list := createTaskList()
+	// 2.创建任务池
+	pool := NewSimpleReturnableTaskPool[*DownloadTask, string](3, list,
+		func(task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			time.Sleep(20 * time.Millisecond)
+			return task.Filename
+		})
+	// 3.以流式模式启动任务池，结果通道缓冲区大小为2
+	results, handle := pool.StartStream(2)
+	// 4.任务完成后实时读取结果，直到通道被关闭
+	count := 0
+	for result := range results {
+		fmt.Printf("任务%d完成，结果：%s\n", result.Index, result.Value)
+		count++
+	}
+	if e := handle.Wait(); e != nil {
+		t.Error(e)
+	}
+	if count != len(list) || len(pool.GetResultList(false)) != 0 {
+		t.Error("流式模式下应当通过通道接收全部结果，且不会收集到结果列表中！")
+	}
+}
+
+// 测试有返回值的并发任务池-流式模式下取消任务池
+func TestReturnableTaskPool_StartStreamCancel(t *testing.T) {
+	// 1.创建任务池
+	pool := NewSimpleReturnableTaskPool[*DownloadTask, string](3, createTaskList(),
+		func(task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			return task.Filename
+		})
+	// 2.以流式模式启动任务池，只读取一个结果后就取消，worker不会一直阻塞在发送结果上
+	results, handle := pool.StartStream(0)
+	<-results
+	handle.Cancel()
+	_ = handle.Wait()
+	if !pool.IsInterrupt() {
+		t.Error("任务池应当被中断！")
+	}
+}
+
+// 测试有返回值的并发任务池-流式模式下取消存在超时任务的任务池
+func TestReturnableTaskPool_StartStreamTimeoutCancel(t *testing.T) {
+	// 1.创建任务池，第一个任务的执行回调函数不响应上下文的取消，超时后仍会在后台运行，其余任务立即完成
+	var started, returned int64
+	pool := NewSimpleReturnableTaskPool[*DownloadTask, string](3, createTaskList(),
+		func(task *DownloadTask, pool *ReturnableTaskPool[*DownloadTask, string]) string {
+			if atomic.AddInt64(&started, 1) == 1 {
+				time.Sleep(150 * time.Millisecond)
+			}
+			atomic.AddInt64(&returned, 1)
+			return task.Filename
+		})
+	pool.SetTaskTimeout(20 * time.Millisecond)
+	// 2.以流式模式启动任务池，不读取结果，在任务超时后取消
+	results, handle := pool.StartStream(0)
+	time.Sleep(50 * time.Millisecond)
+	handle.Cancel()
+	// 3.超时的回调函数返回之后才会关闭通道，且不会向已关闭的通道发送结果
+	for range results {
+	}
+	if atomic.LoadInt64(&returned) != atomic.LoadInt64(&started) {
+		t.Error("结果通道应当在超时的任务执行回调函数返回之后才被关闭！")
+	}
+	_ = handle.Wait()
+	// 4.等待可能的发送，确认不会发生panic
+	time.Sleep(200 * time.Millisecond)
+}
+
+// 自定义超时时间的任务
+type sleepTask time.Duration
+
+// 每个任务的超时时间为其执行时间的一半
+func (task sleepTask) Timeout() time.Duration {
+	return time.Duration(task) / 2
+}
+
+// 测试有返回值的并发任务池-任务自定义超时时间
+func TestReturnableTaskPool_Timeout(t *testing.T) {
+	// 1.创建任务池，前3个任务执行时间较短，会在默认超时时间内完成，但自定义的超时时间更短
+	list := []sleepTask{sleepTask(200 * time.Millisecond), sleepTask(200 * time.Millisecond), sleepTask(200 * time.Millisecond), 0, 0}
+	pool := NewErrorReturnableTaskPool[sleepTask, int](5, 0, 0, list,
+		func(ctx context.Context, task sleepTask, pool *ReturnableTaskPool[sleepTask, int]) (int, error) {
+			select {
+			case <-time.After(time.Duration(task)):
+				return 1, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}, nil, nil)
+	pool.SetTaskTimeout(time.Second)
+	// 2.启动任务池
+	resultList, e := pool.Start(true)
+	fmt.Println(e)
+	if !errors.Is(e, ErrTaskTimeout) || len(resultList) != 2 || len(pool.GetFailureList()) != 3 {
+		t.Error("自定义超时时间的任务应当超时失败！")
+	}
+}
+
+// 测试有返回值的并发任务池-零值的任务以及不可比较的返回值
+func TestReturnableTaskPool_ZeroValue(t *testing.T) {
+	// 1.任务列表中包含零值任务，返回值为不可比较的切片类型
+	list := []int{0, 1, 2, 0, 3}
+	pool := NewSimpleReturnableTaskPool[int, []int](2, list, func(task int, pool *ReturnableTaskPool[int, []int]) []int {
+		if task == 0 {
+			return nil
+		}
+		return []int{task, task * task}
+	})
+	// 2.零值任务同样会被执行，忽略空的结果时nil切片不会被收集
+	resultList, e := pool.Start(true)
+	fmt.Println(resultList)
+	if e != nil || len(resultList) != 3 || len(pool.GetResultList(false)) != 5 {
+		t.Error("零值任务应当被正常执行！")
+	}
 }
\ No newline at end of file
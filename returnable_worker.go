@@ -1,7 +1,7 @@
 package concurrent_task_pool
 
 import (
-	"sync"
+	"context"
 	"time"
 )
 
@@ -12,61 +12,84 @@ import (
 //
 // 一个worker持有一个线程，并一直从任务队列（通道）中获取任务并执行
 // 该worker所执行的任务是有返回值的
-type returnableWorker[T, R comparable] struct {
+type returnableWorker[T, R any] struct {
+	// worker的ID，从1开始编号
+	id int
 	// 自定义任务运行的回调函数
-	run func(task T, pool *ReturnableTaskPool[T, R]) R
-	// 收集存放任务结果的切片引用
-	resultList *[]R
+	run func(ctx context.Context, task T, pool *ReturnableTaskPool[T, R]) (R, error)
 	// 该worker所属的并发任务池对象的引用
 	taskPool *ReturnableTaskPool[T, R]
 }
 
 // returnableWorker 构造函数
-func newReturnableWorker[T, R comparable](run func(T, *ReturnableTaskPool[T, R]) R, result *[]R, pool *ReturnableTaskPool[T, R]) *returnableWorker[T, R] {
+func newReturnableWorker[T, R any](id int, run func(context.Context, T, *ReturnableTaskPool[T, R]) (R, error), pool *ReturnableTaskPool[T, R]) *returnableWorker[T, R] {
 	return &returnableWorker[T, R]{
-		run:        run,
-		resultList: result,
-		taskPool:   pool,
+		id:       id,
+		run:      run,
+		taskPool: pool,
 	}
 }
 
 // 启动worker，该函数会在一个单独的线程中启动并运行worker
-// worker在单独的线程运行，会一直从任务队列中获取任务对象，直到isShutdown为true才结束
-//
-//   - lock 用于收集结果的锁，确保多个worker使用同一个lock
-//   - isShutdown 指示全部任务是否结束的指针，当为true时，worker会在执行完当前任务后立即结束
-//   - ignoreEmpty 是否收集空的任务执行返回值
-func (worker *returnableWorker[T, R]) start(lock *sync.Mutex, isShutdown *bool, ignoreEmpty bool) {
+// worker在单独的线程运行，会一直从任务队列中获取任务对象，任务队列为空时阻塞等待，直到任务池结束全部worker，或者任务并发数减少时才结束
+// 每个任务最终的执行结果会被收集到任务池的结果列表中
+func (worker *returnableWorker[T, R]) start() {
 	// 当前任务池
 	pool := worker.taskPool
-	// 泛型零值
-	var taskZero T
-	var resultZero R
-	// 在新的线程中运行任务
+	// 在新的线程中运行任务，worker的数量已在创建时计入workerGroup
 	go func() {
-		// 除非isShutdown为true，否则将会一直尝试从队列取值
-		for !*isShutdown {
+		defer pool.workerGroup.Done()
+		// 除非任务池结束全部worker、上下文被取消或者worker数量超过任务并发数，否则将会一直从队列取值，队列为空时阻塞等待
+		for {
 			// 从队列取值
-			task := pool.taskQueue.poll()
-			if task == taskZero {
-				continue
-			}
-			// 将当前任务存入当前正在运行的任务集合中
-			pool.runningTasks.add(task)
-			// 延迟执行
-			if pool.workerExecuteDelay > 0 {
-				time.Sleep(pool.workerExecuteDelay)
+			entry, ok := pool.takeEntry()
+			if !ok {
+				return
 			}
-			// 执行任务
-			result := worker.run(task, worker.taskPool)
-			// 收集结果
-			if result != resultZero || (result == resultZero && !ignoreEmpty) {
-				lock.Lock()
-				*worker.resultList = append(*worker.resultList, result)
-				lock.Unlock()
+			task := entry.task
+			entry.attempts++
+			// 等待执行延迟、速率限制以及权重容量，可以执行时当前任务会被存入正在运行的任务集合中，任务池被中断时任务会被放回队列
+			if !pool.waitForExecution(entry) {
+				continue
 			}
-			// 执行完成后，从当前任务列表移除
-			pool.runningTasks.remove(task)
+			// 执行任务，超时的任务会在其回调函数返回之后才处理执行结果
+			pool.notifyStart(entry, worker.id)
+			startTime := time.Now()
+			executeTask(&pool.basePool, task, func(ctx context.Context) (R, error) {
+				return worker.run(ctx, task, worker.taskPool)
+			}, func(result R, e error) {
+				worker.complete(entry, startTime, result, e)
+			})
 		}
 	}()
+}
+
+// 处理一个任务的执行结果，需要在任务执行回调函数返回之后调用
+//
+//   - entry 执行完成的任务条目
+//   - startTime 本次执行任务的开始时间
+//   - result 任务执行的返回值
+//   - e 任务执行的错误
+func (worker *returnableWorker[T, R]) complete(entry *taskEntry[T], startTime time.Time, result R, e error) {
+	pool := worker.taskPool
+	duration := time.Since(startTime)
+	pool.observeExecution(duration, e)
+	pool.releaseLimits(entry)
+	// 收集结果，会被重试的失败任务不收集
+	if pool.completeExecution(entry, worker.id, duration, e) {
+		pool.collectResult(&TaskResult[T, R]{
+			Task:      entry.task,
+			Index:     entry.index,
+			Value:     result,
+			Err:       e,
+			Attempts:  entry.attempts,
+			StartTime: startTime,
+			Duration:  duration,
+		})
+		pool.completeDependencies(entry, e == nil)
+	}
+	// 执行完成后，从当前任务列表移除
+	pool.runningTasks.remove(entry)
+	pool.finish()
+	pool.propagateIfPanic(e)
 }
\ No newline at end of file
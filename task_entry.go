@@ -0,0 +1,72 @@
+package concurrent_task_pool
+
+// taskEntry 是任务队列中的一个任务条目，包装了任务对象以及该任务的执行状态
+type taskEntry[T any] struct {
+	// 任务对象
+	task T
+	// 任务的提交序号，初始任务列表中任务的序号即为其下标，之后提交的任务序号依次递增
+	index int
+	// 该任务已经被执行的次数
+	attempts int
+	// 任务的优先级，仅在启用优先级队列时生效，值越大越先被执行
+	priority int
+	// 该任务当前执行时占用的权重，仅在启用权重模式时生效，为0表示未占用
+	weight int
+	// 是否已经预订了速率限制的令牌，为true时下一次被取出时可以直接执行
+	permitted bool
+}
+
+// taskQueue 是存放任务条目的队列，默认为先进先出的 arrayQueue ，启用优先级队列时为 priorityQueue
+type taskQueue[T any] interface {
+	// 任务条目入队
+	offer(entry *taskEntry[T])
+	// 阻塞地取出一个任务条目，直到有元素入队，或者stop返回true
+	take(stop func() bool) (*taskEntry[T], bool)
+	// 唤醒全部正在 take 中等待的线程
+	wakeAll()
+	// 按照出队的先后顺序返回全部任务条目
+	toSlice() []*taskEntry[T]
+}
+
+// 创建一个新的任务条目
+//
+//   - task 任务对象
+//   - index 任务的提交序号
+//
+// 返回包装了任务对象的任务条目，其执行次数为0
+func newTaskEntry[T any](task T, index int) *taskEntry[T] {
+	return &taskEntry[T]{
+		task:      task,
+		index:     index,
+		attempts:  0,
+		priority:  0,
+		weight:    0,
+		permitted: false,
+	}
+}
+
+// 从一个现有的任务切片创建任务队列
+//
+//   - taskList 任务切片，下标为0的任务会被放置于队头
+//
+// 返回包含了全部任务条目的任务队列
+func newTaskEntryQueue[T any](taskList []T) *arrayQueue[*taskEntry[T]] {
+	entries := make([]*taskEntry[T], 0, len(taskList))
+	for i, task := range taskList {
+		entries = append(entries, newTaskEntry(task, i))
+	}
+	return newArrayQueueFromSlice(entries)
+}
+
+// 从任务条目切片中取出全部任务对象
+//
+//   - entries 任务条目切片
+//
+// 返回任务对象切片，顺序与任务条目切片一致
+func entriesToTasks[T any](entries []*taskEntry[T]) []T {
+	taskList := make([]T, 0, len(entries))
+	for _, entry := range entries {
+		taskList = append(taskList, entry.task)
+	}
+	return taskList
+}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package concurrent_task_pool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPoolClosed 表示任务池已被关闭、已经结束或者已被中断，无法再提交新的任务
+var ErrPoolClosed = errors.New("任务池已关闭，无法提交新的任务")
+
+// ErrTaskTimeout 表示任务执行超时，超时的任务会被视为执行失败，同样适用重试策略
+var ErrTaskTimeout = errors.New("任务执行超时")
+
+// ErrDependencyCycle 表示任务之间存在循环依赖，此时任务池不会启动
+var ErrDependencyCycle = errors.New("任务之间存在循环依赖")
+
+// ErrDependencyNotFound 表示任务依赖了不存在的任务
+var ErrDependencyNotFound = errors.New("依赖的任务不存在")
+
+// ErrDuplicateTaskID 表示启用依赖关系时，存在ID重复的任务
+var ErrDuplicateTaskID = errors.New("任务ID重复")
+
+// ErrDependencyFailed 表示任务依赖的任务执行失败，因此该任务不会被执行
+var ErrDependencyFailed = errors.New("依赖的任务执行失败")
+
+// TaskFailure 表示一个执行失败的任务，包含了任务对象以及任务执行时返回的错误
+type TaskFailure[T any] struct {
+	// 执行失败的任务对象
+	Task T
+	// 任务执行时返回的错误
+	Err error
+	// 该任务失败时已经被执行的次数
+	Attempts int
+}
+
+// Error 返回任务失败的错误信息
+func (failure *TaskFailure[T]) Error() string {
+	return fmt.Sprintf("任务%v执行失败：%s", failure.Task, failure.Err)
+}
+
+// Unwrap 返回任务执行时的原始错误，使其可被 errors.Is 和 errors.As 判断
+func (failure *TaskFailure[T]) Unwrap() error {
+	return failure.Err
+}
+
+// PanicError 表示任务执行回调函数在执行过程中发生了panic
+// worker会恢复该panic，并将其转换为任务的执行失败
+type PanicError struct {
+	// 调用panic时传入的值
+	Value any
+	// 发生panic时的调用栈
+	Stack []byte
+}
+
+// Error 返回panic的错误信息，包含panic的值以及调用栈
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("任务执行时发生panic：%v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap 当panic的值是一个错误时，返回该错误
+func (e *PanicError) Unwrap() error {
+	if valueError, ok := e.Value.(error); ok {
+		return valueError
+	}
+	return nil
+}
+
+// 从任务失败记录切片中取出全部任务对象
+//
+//   - failures 任务失败记录切片
+//
+// 返回任务对象切片，顺序与失败记录一致
+func failuresToTasks[T any](failures []*TaskFailure[T]) []T {
+	taskList := make([]T, 0, len(failures))
+	for _, failure := range failures {
+		taskList = append(taskList, failure.Task)
+	}
+	return taskList
+}
+
+// PoolError 是任务池执行完成后，由全部失败任务聚合而成的错误
+type PoolError[T any] struct {
+	// 全部执行失败的任务
+	Failures []*TaskFailure[T]
+}
+
+// Error 返回聚合后的错误信息，每个失败任务占一行
+func (e *PoolError[T]) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("共有%d个任务执行失败：", len(e.Failures)))
+	for _, failure := range e.Failures {
+		builder.WriteString("\n")
+		builder.WriteString(failure.Error())
+	}
+	return builder.String()
+}
+
+// Is 判断是否存在与target匹配的失败任务错误，使聚合后的错误可被 errors.Is 判断
+//
+//   - target 要匹配的错误
+//
+// 任意一个失败任务的错误与target匹配时返回true
+func (e *PoolError[T]) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if errors.Is(failure, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 查找第一个能够赋值给target的失败任务错误，使聚合后的错误可被 errors.As 判断
+//
+//   - target 指向接收错误的变量的指针
+//
+// 找到时将其赋值给target并返回true
+func (e *PoolError[T]) As(target any) bool {
+	for _, failure := range e.Failures {
+		if errors.As(failure, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap 返回每个失败任务对应的错误
+func (e *PoolError[T]) Unwrap() []error {
+	errorList := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errorList = append(errorList, failure)
+	}
+	return errorList
+}
\ No newline at end of file
@@ -0,0 +1,69 @@
+package concurrent_task_pool
+
+import "context"
+
+// TaskHandle 是异步启动任务池后返回的句柄，可用于等待任务池结束、取消任务池以及获取任务池执行的错误
+type TaskHandle struct {
+	// 任务池结束时被关闭的通道
+	done chan struct{}
+	// 任务池结束后返回的错误
+	err error
+	// 取消任务池父上下文的函数
+	cancel context.CancelFunc
+}
+
+// 创建一个任务池句柄
+//
+//   - cancel 取消任务池父上下文的函数
+//
+// 返回任务池句柄对象指针
+func newTaskHandle(cancel context.CancelFunc) *TaskHandle {
+	return &TaskHandle{
+		done:   make(chan struct{}),
+		err:    nil,
+		cancel: cancel,
+	}
+}
+
+// 标记任务池已结束，并记录任务池返回的错误
+//
+//   - e 任务池返回的错误
+func (handle *TaskHandle) finish(e error) {
+	handle.err = e
+	handle.cancel()
+	close(handle.done)
+}
+
+// Wait 阻塞等待直到任务池结束
+//
+// 返回任务池执行完成后聚合了全部失败任务的错误，与 Start 方法的返回值相同
+func (handle *TaskHandle) Wait() error {
+	<-handle.done
+	return handle.err
+}
+
+// Done 返回一个在任务池结束时被关闭的通道，可用于在select中等待任务池结束
+func (handle *TaskHandle) Done() <-chan struct{} {
+	return handle.done
+}
+
+// Cancel 取消任务池，任务池会被中断，传递给任务执行回调函数的上下文也会被取消
+// 调用该方法后仍可通过 Wait 方法等待任务池结束
+func (handle *TaskHandle) Cancel() {
+	handle.cancel()
+}
+
+// ReturnableTaskHandle 是异步启动有返回值的任务池后返回的句柄，除了 TaskHandle 的功能外，还可以获取任务的返回值
+type ReturnableTaskHandle[R any] struct {
+	*TaskHandle
+	// 全部任务执行后的返回值列表
+	results []R
+}
+
+// Results 阻塞等待直到任务池结束，并返回全部任务执行后的返回值列表
+//
+// 返回值列表，与 Start 方法的第一个返回值相同
+func (handle *ReturnableTaskHandle[R]) Results() []R {
+	<-handle.done
+	return handle.results
+}
\ No newline at end of file
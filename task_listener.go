@@ -0,0 +1,83 @@
+package concurrent_task_pool
+
+import "time"
+
+// TaskEvent 是任务生命周期中的一个事件，包含了事件发生时任务的相关信息
+type TaskEvent[T any] struct {
+	// 任务对象
+	Task T
+	// 任务的提交序号，初始任务列表中任务的序号即为其下标，之后通过 Submit 等方法提交的任务序号依次递增
+	Index int
+	// 执行该任务的worker的ID，从1开始编号，任务不是由worker执行时（例如入队、因依赖失败而不会被执行）为0
+	WorkerID int
+	// 任务当前已经被执行的次数，包括本次执行，入队时为0
+	Attempt int
+	// 本次执行任务的耗时，仅在任务执行完成的事件中有效
+	Duration time.Duration
+	// 本次执行任务的错误，执行成功时为nil
+	Err error
+}
+
+// TaskListener 是任务生命周期事件的监听器，可通过任务池的 AddListener 方法注册
+// 监听器的方法会在worker的线程中被同步调用，因此需要能够被并发调用，且应当尽快返回，避免阻塞任务的执行
+// 只需要监听部分事件时，可以嵌入 BaseTaskListener ，并仅实现需要的方法
+type TaskListener[T any] interface {
+	// OnQueued 任务被提交到任务队列时调用，任务池启动时也会为初始任务列表中的每个任务调用
+	// 执行失败后被自动重试的任务不会再次调用该方法
+	OnQueued(event *TaskEvent[T])
+	// OnStart worker开始执行任务时调用
+	OnStart(event *TaskEvent[T])
+	// OnSuccess 任务执行成功时调用
+	OnSuccess(event *TaskEvent[T])
+	// OnFailure 任务最终执行失败，不会再被重试时调用，因依赖执行失败而不会被执行的任务同样会调用该方法
+	OnFailure(event *TaskEvent[T])
+	// OnRetry 任务执行失败，并且会按照重试策略被重试时调用
+	OnRetry(event *TaskEvent[T])
+	// OnInterrupt 任务池被中断时调用，每个任务池只会调用一次
+	OnInterrupt()
+	// OnPoolDone 任务池运行结束时调用，参数为任务池启动方法返回的错误
+	// 若任务池被中断，则正在执行的任务的事件可能在该方法之后被调用
+	OnPoolDone(e error)
+}
+
+// BaseTaskListener 是 TaskListener 的空实现，全部方法都不执行任何操作
+// 可以嵌入到自定义的监听器中，使其仅实现需要监听的事件
+type BaseTaskListener[T any] struct{}
+
+// OnQueued 不执行任何操作
+func (listener BaseTaskListener[T]) OnQueued(event *TaskEvent[T]) {}
+
+// OnStart 不执行任何操作
+func (listener BaseTaskListener[T]) OnStart(event *TaskEvent[T]) {}
+
+// OnSuccess 不执行任何操作
+func (listener BaseTaskListener[T]) OnSuccess(event *TaskEvent[T]) {}
+
+// OnFailure 不执行任何操作
+func (listener BaseTaskListener[T]) OnFailure(event *TaskEvent[T]) {}
+
+// OnRetry 不执行任何操作
+func (listener BaseTaskListener[T]) OnRetry(event *TaskEvent[T]) {}
+
+// OnInterrupt 不执行任何操作
+func (listener BaseTaskListener[T]) OnInterrupt() {}
+
+// OnPoolDone 不执行任何操作
+func (listener BaseTaskListener[T]) OnPoolDone(e error) {}
+
+// 创建一个任务条目对应的事件
+//
+//   - entry 任务条目
+//   - workerID 执行该任务的worker的ID，不是由worker执行时为0
+//
+// 返回任务事件，其耗时与错误为零值
+func newTaskEvent[T any](entry *taskEntry[T], workerID int) *TaskEvent[T] {
+	return &TaskEvent[T]{
+		Task:     entry.task,
+		Index:    entry.index,
+		WorkerID: workerID,
+		Attempt:  entry.attempts,
+		Duration: 0,
+		Err:      nil,
+	}
+}
\ No newline at end of file
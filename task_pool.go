@@ -1,6 +1,7 @@
 package concurrent_task_pool
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
@@ -8,21 +9,24 @@ import (
 )
 
 // TaskPool 并发任务池，用于执行指定数量的并发多任务，其中任务是无返回值的
-type TaskPool[T comparable] struct {
+type TaskPool[T any] struct {
 	basePool[T]
 	// 执行每个任务的回调函数逻辑
 	//
 	// 回调函数参数：
 	//
+	//  - ctx 任务池运行时的上下文，当任务池被中断、接收到终止信号或者父上下文被取消时，该上下文会被取消
 	//  - task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
 	//  - taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
-	run func(task T, taskPool *TaskPool[T])
+	//
+	// 返回值：任务执行失败时返回的错误，成功时返回nil
+	run func(ctx context.Context, task T, taskPool *TaskPool[T]) error
 	// 接收到终止信号后的操作，可以指定为nil
 	//
 	// 参数为当前并发任务池对象，可从其中获取任务状态并执行保存
 	shutdown func(taskPool *TaskPool[T])
 	// 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil
-	// 该回调函数会在任务池执行任务时被不间断调用
+	// 该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 	// 任务池全部任务执行完成后，该回调函数不会再被调用
 	//
 	// 参数为当前并发任务池对象，可从中实时读取任务池状态
@@ -45,23 +49,77 @@ type TaskPool[T comparable] struct {
 //   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
 //     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
 //   - lookupFunction 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil，
-//     该回调函数会在任务池执行任务时被不间断调用
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
+//     任务池全部任务执行完成后，该回调函数不会再被调用
+//     其参数为：
+//     taskPool 并发任务池本身，可从中实时读取任务池状态
+//
+// 返回一个新建的无返回值的并发任务池对象指针
+func NewTaskPool[T any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(task T, taskPool *TaskPool[T]), shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
+	return NewContextTaskPool[T](concurrent, createInterval, executeDelay, taskList, func(ctx context.Context, task T, taskPool *TaskPool[T]) {
+		runFunction(task, taskPool)
+	}, shutdownFunction, lookupFunction)
+}
+
+// NewContextTaskPool 通过现有的任务列表创建任务池，其任务执行回调函数能够接收任务池的上下文
+// 当任务池被中断、接收到终止信号或者启动任务池时传入的父上下文被取消时，该上下文会被取消，可用于及时终止正在执行的网络请求等操作
+//
+//   - concurrent 任务并发数，即worker数量，每一个worker负责在一个单独的线程中运行任务，当队列中任务数量足够时，并发任务池会一直保持有concurrent个任务一直在并发运行
+//   - createInterval 创建worker时的时间间隔
+//     若设为0则会在开启并发任务池时同时创建完成全部worker
+//     该参数不影响任务池执行时worker从队列取出任务的速度，仅仅代表任务池初始化时创建worker的间隔
+//   - executeDelay worker执行每个任务之前的延迟
+//     若设为0则所有worker每次从任务队列取出任务后就立即执行
+//     否则，当worker每次从任务队列取出任务时，会延迟一段时间再执行任务
+//   - taskList 存放全部任务的切片
+//   - runFunction 自定义执行任务逻辑的回调函数，其参数为：
+//     ctx 任务池运行时的上下文，任务池被中断时会被取消
+//     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
+//     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
+//   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
+//     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
+//   - lookupFunction 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil，
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
+//     任务池全部任务执行完成后，该回调函数不会再被调用
+//     其参数为：
+//     taskPool 并发任务池本身，可从中实时读取任务池状态
+//
+// 返回一个新建的无返回值的并发任务池对象指针
+func NewContextTaskPool[T any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(ctx context.Context, task T, taskPool *TaskPool[T]), shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
+	return NewErrorTaskPool[T](concurrent, createInterval, executeDelay, taskList, func(ctx context.Context, task T, taskPool *TaskPool[T]) error {
+		runFunction(ctx, task, taskPool)
+		return nil
+	}, shutdownFunction, lookupFunction)
+}
+
+// NewErrorTaskPool 通过现有的任务列表创建任务池，其任务执行回调函数能够接收任务池的上下文，并返回任务执行的错误
+// 任务返回的错误会被任务池记录，可通过 GetFailedTaskList 获取失败的任务，任务池执行完成后， Start 方法会返回聚合了全部失败任务的错误
+//
+//   - concurrent 任务并发数，即worker数量，每一个worker负责在一个单独的线程中运行任务，当队列中任务数量足够时，并发任务池会一直保持有concurrent个任务一直在并发运行
+//   - createInterval 创建worker时的时间间隔
+//     若设为0则会在开启并发任务池时同时创建完成全部worker
+//     该参数不影响任务池执行时worker从队列取出任务的速度，仅仅代表任务池初始化时创建worker的间隔
+//   - executeDelay worker执行每个任务之前的延迟
+//     若设为0则所有worker每次从任务队列取出任务后就立即执行
+//     否则，当worker每次从任务队列取出任务时，会延迟一段时间再执行任务
+//   - taskList 存放全部任务的切片
+//   - runFunction 自定义执行任务逻辑的回调函数，其参数为：
+//     ctx 任务池运行时的上下文，任务池被中断时会被取消
+//     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
+//     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
+//     返回值：任务执行失败时返回的错误，成功时返回nil
+//   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
+//     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
+//   - lookupFunction 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil，
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 //     任务池全部任务执行完成后，该回调函数不会再被调用
 //     其参数为：
 //     taskPool 并发任务池本身，可从中实时读取任务池状态
 //
 // 返回一个新建的无返回值的并发任务池对象指针
-func NewTaskPool[T comparable](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(task T, taskPool *TaskPool[T]), shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
+func NewErrorTaskPool[T any](concurrent int, createInterval, executeDelay time.Duration, taskList []T, runFunction func(ctx context.Context, task T, taskPool *TaskPool[T]) error, shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
 	return &TaskPool[T]{
-		basePool: basePool[T]{
-			concurrent:         concurrent,
-			taskCreateInterval: createInterval,
-			workerExecuteDelay: executeDelay,
-			taskQueue:          newArrayQueueFromSlice(taskList),
-			runningTasks:       newMapSet[T](),
-			isInterrupt:        false,
-			isAutoSaving:       false,
-		},
+		basePool: newBasePool(concurrent, createInterval, executeDelay, taskList),
 		run:      runFunction,
 		shutdown: shutdownFunction,
 		lookup:   lookupFunction,
@@ -80,7 +138,7 @@ func NewTaskPool[T comparable](concurrent int, createInterval, executeDelay time
 //   - runFunction 自定义执行任务逻辑的回调函数，其参数为：
 //     task 从任务队列中取出的一个任务对象，该任务对象可在该函数中被处理并进一步执行任务，该函数调用在一个单独的线程中运行
 //     taskPool 并发任务池本身，可通过任务池对象进行重试操作或者中断等
-func NewSimpleTaskPool[T comparable](concurrent int, taskList []T, runFunction func(task T, taskPool *TaskPool[T])) *TaskPool[T] {
+func NewSimpleTaskPool[T any](concurrent int, taskList []T, runFunction func(task T, taskPool *TaskPool[T])) *TaskPool[T] {
 	return NewTaskPool[T](concurrent, 0, 0, taskList, runFunction, nil, nil)
 }
 
@@ -98,18 +156,38 @@ func NewSimpleTaskPool[T comparable](concurrent int, taskList []T, runFunction f
 //   - shutdownFunction 接收到终止信号后的自定义停机逻辑回调函数，可以指定为nil，其参数为：
 //     taskPool 并发任务池本身，可在每个任务执行时通过该任务池访问任务池中的队列或者中断任务池等
 //   - lookupFunction 任务池执行时，可用于实时查看任务池状态的自定义回调函数，可以指定为nil，
-//     该回调函数会在任务池执行任务时被不间断调用
+//     该回调函数会在任务池执行任务时被定时调用，调用间隔可通过 SetLookupInterval 设定
 //     任务池全部任务执行完成后，该回调函数不会再被调用
 //     其参数为：
 //     taskPool 并发任务池本身，可从中实时读取任务池状态
-func NewNoDelayTaskPool[T comparable](concurrent int, taskList []T, runFunction func(task T, taskPool *TaskPool[T]), shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
+func NewNoDelayTaskPool[T any](concurrent int, taskList []T, runFunction func(task T, taskPool *TaskPool[T]), shutdownFunction func(taskPool *TaskPool[T]), lookupFunction func(taskPool *TaskPool[T])) *TaskPool[T] {
 	return NewTaskPool[T](concurrent, 0, 0, taskList, runFunction, shutdownFunction, lookupFunction)
 }
 
 // Start 启动并发任务池
-func (pool *TaskPool[T]) Start() {
-	// 用于控制worker运行的变量，当为false时全部worker将一直等待从任务取出任务执行，否则都会立即停止运行
-	workerShutdown := false
+//
+// 全部任务执行完成后，若存在执行失败的任务，则返回聚合了全部失败任务的错误 *PoolError ，否则返回nil
+func (pool *TaskPool[T]) Start() error {
+	return pool.StartContext(context.Background())
+}
+
+// StartContext 使用给定的父上下文启动并发任务池
+// 当父上下文被取消时，任务池会被中断，且传递给任务执行回调函数的上下文也会被取消
+//
+//   - ctx 父上下文
+//
+// 全部任务执行完成后，若存在执行失败的任务，则返回聚合了全部失败任务的错误 *PoolError ，否则返回nil
+// 启用依赖关系时，若依赖关系无效（例如存在循环依赖），则任务池不会启动，并返回对应的错误
+func (pool *TaskPool[T]) StartContext(ctx context.Context) error {
+	// 校验任务的依赖关系
+	if e := pool.validateDependencies(); e != nil {
+		return e
+	}
+	// 初始化任务池上下文
+	pool.initContext(ctx)
+	defer pool.cancelContext()
+	// 通知监听器初始任务列表中的任务入队
+	pool.notifyInitialQueued()
 	// 在一个新的线程接收终止信号
 	var signals chan os.Signal
 	if pool.shutdown != nil {
@@ -119,36 +197,47 @@ func (pool *TaskPool[T]) Start() {
 			// 等待信号
 			s := <-signals
 			if s != nil {
-				// 结束全部worker
-				workerShutdown = true
-				// 执行shutdown回调
-				pool.shutdown(pool)
-				// 标记为中断
-				pool.isInterrupt = true
+				pool.handleSignal(func() {
+					pool.shutdown(pool)
+				})
 			}
 		}()
 	}
-	// 创建worker
-	for i := 0; i < pool.concurrent; i++ {
-		eachWorker := newWorker[T](pool.run, pool)
-		eachWorker.start(&workerShutdown)
-		if pool.taskCreateInterval > 0 {
-			time.Sleep(pool.taskCreateInterval)
-		}
-	}
-	// 等待直到任务池全部任务完成
-	// 如果被标记为中断，则会立即退出
-	for !pool.isInterrupt && !pool.IsAllDone() {
-		// 执行lookup函数
-		if pool.lookup != nil {
+	// 按照任务并发数创建worker，任务池运行期间修改任务并发数时也会通过该函数创建新的worker
+	pool.startWorkers(func(id int) {
+		newWorker[T](id, pool.run, pool).start()
+	})
+	// 阻塞等待直到任务池全部任务完成，期间定时执行lookup函数
+	// 如果被标记为中断，或者上下文被取消，则会立即退出
+	var lookup func()
+	if pool.lookup != nil {
+		lookup = func() {
 			pool.lookup(pool)
 		}
 	}
-	// 结束全部worker
-	workerShutdown = true
+	pool.waitDone(lookup)
+	// 结束全部worker，并不再接收新的任务
+	pool.shutdownWorkers()
+	pool.isClosed.set(true)
 	// 关闭信号接收通道
 	if signals != nil {
 		signal.Stop(signals)
 		close(signals)
 	}
+	// 通知监听器任务池运行结束
+	e := pool.aggregateError()
+	pool.notifyDone(e)
+	return e
+}
+
+// StartAsync 在一个新的线程中启动并发任务池，不会阻塞当前线程
+//
+// 返回任务池句柄，可通过句柄等待任务池结束、取消任务池或者获取任务池执行的错误
+func (pool *TaskPool[T]) StartAsync() *TaskHandle {
+	ctx, cancel := context.WithCancel(context.Background())
+	handle := newTaskHandle(cancel)
+	go func() {
+		handle.finish(pool.StartContext(ctx))
+	}()
+	return handle
 }
\ No newline at end of file
@@ -1,7 +1,16 @@
 package concurrent_task_pool
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -12,8 +21,8 @@ type DownloadTask struct {
 	Url string
 	// 文件名
 	Filename string
-	// 进度(0-100)
-	Process int
+	// 进度(0-100)，可能在lookup回调函数中被并发读取，因此使用原子操作访问
+	Process int32
 }
 
 // 创建示例任务对象列表
@@ -154,7 +163,7 @@ func TestTaskPool_LookupTasks(t *testing.T) {
 		func(task *DownloadTask, pool *TaskPool[*DownloadTask]) {
 			// 模拟执行任务
 			for i := 0; i < 10; i++ {
-				task.Process += 10
+				atomic.AddInt32(&task.Process, 10)
 				time.Sleep(100 * time.Millisecond)
 			}
 		},
@@ -174,10 +183,1055 @@ func TestTaskPool_LookupTasks(t *testing.T) {
 			tasks := pool.GetRunningTaskList()
 			// 遍历获取全部任务状态
 			for _, task := range tasks {
-				fmt.Printf("正在下载：%s，进度：%d%%\n", task.Filename, task.Process)
+				fmt.Printf("正在下载：%s，进度：%d%%\n", task.Filename, atomic.LoadInt32(&task.Process))
 			}
 			time.Sleep(50 * time.Millisecond)
 		})
 	// 3.启动任务池
 	pool.Start()
+}
+
+// 测试无返回值的并发任务池-通过上下文取消任务
+func TestTaskPool_StartContext(t *testing.T) {
+	// 1.创建任务列表
+	list := createTaskList()
+	// 2.创建任务池，任务执行回调函数可接收上下文
+	pool := NewContextTaskPool[*DownloadTask](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *TaskPool[*DownloadTask]) {
+			fmt.Printf("正在下载：%s...\n", task.Filename)
+			// 模拟执行任务，上下文被取消时立即结束
+			for i := 0; i < 4; i++ {
+				select {
+				case <-ctx.Done():
+					fmt.Printf("下载%s被取消！\n", task.Filename)
+					return
+				case <-time.After(100 * time.Millisecond):
+					task.Process += 25
+				}
+			}
+			fmt.Printf("下载%s完成！\n", task.Filename)
+		}, nil, nil)
+	// 3.使用带超时的上下文启动任务池
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	pool.StartContext(ctx)
+	if !pool.IsInterrupt() {
+		t.Error("上下文超时后任务池应当被标记为中断！")
+	}
+}
+
+// 测试无返回值的并发任务池-任务返回错误
+func TestTaskPool_Error(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池，任务执行回调函数可返回错误
+	pool := NewErrorTaskPool[*DownloadTask](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *TaskPool[*DownloadTask]) error {
+			fmt.Printf("正在下载：%s...\n", task.Filename)
+			// 模拟出现错误
+			if task.Url == "" {
+				return fmt.Errorf("文件%s的下载地址为空", task.Filename)
+			}
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}, nil, nil)
+	// 3.启动任务池，全部任务完成后获取聚合的错误
+	e := pool.Start()
+	fmt.Println(e)
+	// 4.获取执行失败的任务
+	failedTasks := pool.GetFailedTaskList()
+	if e == nil || len(failedTasks) != 1 || failedTasks[0] != list[2] {
+		t.Error("应当有一个任务执行失败！")
+	}
+}
+
+// 测试无返回值的并发任务池-重试策略
+func TestTaskPool_RetryPolicy(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池
+	pool := NewErrorTaskPool[*DownloadTask](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *TaskPool[*DownloadTask]) error {
+			// 模拟一个一直失败的任务
+			if task.Url == "" {
+				fmt.Printf("下载%s出现错误！\n", task.Filename)
+				return fmt.Errorf("文件%s的下载地址为空", task.Filename)
+			}
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}, nil, nil)
+	// 3.设定重试策略，最多执行3次，指数退避
+	pool.SetRetryPolicy(NewExponentialRetryPolicy(3, 50*time.Millisecond, time.Second))
+	// 4.启动任务池
+	e := pool.Start()
+	fmt.Println(e)
+	// 5.重试后仍失败的任务会被放入死信任务列表
+	deadTasks := pool.GetDeadTaskList()
+	if len(deadTasks) != 1 || pool.GetFailureList()[0].Attempts != 3 {
+		t.Error("失败的任务应当在执行3次后被放入死信任务列表！")
+	}
+}
+
+// 测试无返回值的并发任务池-任务执行时发生panic
+func TestTaskPool_Panic(t *testing.T) {
+	// 1.创建任务队列
+	list := createTaskListWithError()
+	// 2.创建任务池
+	pool := NewTaskPool[*DownloadTask](3, 0, 0, list,
+		// 每个任务的自定义执行逻辑回调函数
+		func(task *DownloadTask, pool *TaskPool[*DownloadTask]) {
+			// 模拟发生panic
+			if task.Url == "" {
+				panic("下载地址为空")
+			}
+			time.Sleep(100 * time.Millisecond)
+		}, nil, nil)
+	// 3.启动任务池，panic会被转换为任务的失败
+	e := pool.Start()
+	var panicError *PanicError
+	if !errors.As(e, &panicError) || len(pool.GetFailedTaskList()) != 1 {
+		t.Error("发生panic的任务应当被记录为失败！")
+		return
+	}
+	fmt.Printf("panic的值：%v\n", panicError.Value)
+}
+
+// 读取当前进程执行Go代码所消耗的CPU时间估计值（秒），若运行时不支持该指标则返回-1
+func readUserCPUSeconds() float64 {
+	sample := []metrics.Sample{{Name: "/cpu/classes/user:cpu-seconds"}}
+	runtime.GC()
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return -1
+	}
+	return sample[0].Value.Float64()
+}
+
+// 测试执行耗时任务时并发任务池的CPU占用，worker等待任务与任务池等待完成时均不应占用CPU
+func BenchmarkTaskPool_SlowTasks(b *testing.B) {
+	start := readUserCPUSeconds()
+	for i := 0; i < b.N; i++ {
+		// 6个耗时任务，3个worker，其中一部分时间worker处于空闲状态
+		pool := NewSimpleTaskPool[int](3, []int{1, 2, 3, 4, 5, 6, 7}, func(task int, pool *TaskPool[int]) {
+			time.Sleep(50 * time.Millisecond)
+		})
+		pool.Start()
+	}
+	if start >= 0 {
+		b.ReportMetric((readUserCPUSeconds()-start)/float64(b.N), "cpu-seconds/op")
+	}
+}
+
+// 测试任务池在长时间等待一个任务时的CPU占用，此时大部分worker处于空闲状态，并且指定了lookup函数
+func BenchmarkTaskPool_Idle(b *testing.B) {
+	start := readUserCPUSeconds()
+	for i := 0; i < b.N; i++ {
+		pool := NewNoDelayTaskPool[int](4, []int{1}, func(task int, pool *TaskPool[int]) {
+			time.Sleep(200 * time.Millisecond)
+		}, nil, func(pool *TaskPool[int]) {
+			_ = pool.GetRunningTaskList()
+		})
+		pool.Start()
+	}
+	if start >= 0 {
+		b.ReportMetric((readUserCPUSeconds()-start)/float64(b.N), "cpu-seconds/op")
+	}
+}
+
+// 并发压力测试：大量短任务，同时进行自动重试、读取任务列表以及保存任务，需使用 go test -race 运行以检查数据竞争
+func TestTaskPool_Stress(t *testing.T) {
+	// 1.创建大量任务
+	list := make([]int, 0)
+	for i := 1; i <= 5000; i++ {
+		list = append(list, i)
+	}
+	file := filepath.Join(t.TempDir(), "tasks.json")
+	// 已执行的次数
+	var executed int64
+	// 2.创建任务池
+	pool := NewErrorTaskPool[int](16, 0, 0, list,
+		func(ctx context.Context, task int, pool *TaskPool[int]) error {
+			atomic.AddInt64(&executed, 1)
+			// 模拟一部分任务一直失败
+			if task%100 == 0 {
+				return fmt.Errorf("任务%d执行失败", task)
+			}
+			return nil
+		}, nil,
+		// 并发读取任务池状态并保存任务
+		func(pool *TaskPool[int]) {
+			_ = pool.GetAllTaskList()
+			_ = pool.GetFailedTaskList()
+			_ = pool.IsAllDone()
+			if e := pool.SaveTaskList(file); e != nil {
+				t.Error(e)
+			}
+		})
+	pool.SetRetryPolicy(NewFixedRetryPolicy(2, time.Millisecond))
+	pool.SetLookupInterval(time.Millisecond)
+	// 3.启动任务池
+	_ = pool.Start()
+	// 4.每个失败的任务都应当被执行两次
+	if len(pool.GetFailedTaskList()) != 50 || atomic.LoadInt64(&executed) != 5050 {
+		t.Errorf("失败任务数：%d，执行次数：%d", len(pool.GetFailedTaskList()), atomic.LoadInt64(&executed))
+	}
+}
+
+// 并发压力测试：在其它线程中中断正在执行大量任务的任务池，需使用 go test -race 运行以检查数据竞争
+func TestTaskPool_StressInterrupt(t *testing.T) {
+	// 1.创建大量任务
+	list := make([]int, 0)
+	for i := 1; i <= 5000; i++ {
+		list = append(list, i)
+	}
+	// 2.创建任务池
+	pool := NewContextTaskPool[int](16, 0, 0, list,
+		func(ctx context.Context, task int, pool *TaskPool[int]) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Millisecond):
+			}
+		}, nil, nil)
+	// 3.在其它线程中中断任务池
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		pool.Interrupt()
+	}()
+	_ = pool.Start()
+	if !pool.IsInterrupt() || pool.IsAllDone() {
+		t.Error("任务池应当被中断！")
+	}
+}
+
+// 测试开放模式的并发任务池，在任务池运行期间持续提交任务
+func TestTaskPool_Submit(t *testing.T) {
+	// 已执行的任务数
+	var executed int64
+	// 1.创建一个没有初始任务的任务池
+	pool := NewSimpleTaskPool[*DownloadTask](3, nil,
+		func(task *DownloadTask, pool *TaskPool[*DownloadTask]) {
+			time.Sleep(50 * time.Millisecond)
+			fmt.Printf("下载%s完成！\n", task.Filename)
+			atomic.AddInt64(&executed, 1)
+		})
+	// 2.设为开放模式，任务队列为空时任务池也不会结束
+	pool.Open()
+	// 3.在其它线程中模拟生产者持续提交任务，完成后关闭任务池
+	go func() {
+		for _, task := range createTaskList()[:10] {
+			_ = pool.Submit(task)
+			time.Sleep(20 * time.Millisecond)
+		}
+		_ = pool.SubmitBatch(createTaskList()[10:20])
+		pool.Close()
+	}()
+	// 4.启动任务池，直到任务池被关闭且剩余任务执行完成后才会返回
+	_ = pool.Start()
+	if atomic.LoadInt64(&executed) != 20 {
+		t.Errorf("应当执行20个任务，实际执行%d个", atomic.LoadInt64(&executed))
+	}
+	if pool.Submit(&DownloadTask{}) != ErrPoolClosed {
+		t.Error("任务池结束后不应当能够提交任务！")
+	}
+}
+
+// 测试异步启动并发任务池，并同时等待多个任务池
+func TestTaskPool_StartAsync(t *testing.T) {
+	// 1.创建两个任务池
+	createPool := func() *TaskPool[*DownloadTask] {
+		return NewSimpleTaskPool[*DownloadTask](3, createTaskList()[:6], func(task *DownloadTask, pool *TaskPool[*DownloadTask]) {
+			time.Sleep(100 * time.Millisecond)
+		})
+	}
+	first, second := createPool(), createPool()
+	// 2.异步启动任务池，不会阻塞当前线程
+	firstHandle, secondHandle := first.StartAsync(), second.StartAsync()
+	// 3.取消第二个任务池
+	secondHandle.Cancel()
+	// 4.通过select等待任务池结束
+	for firstHandle != nil || secondHandle != nil {
+		select {
+		case <-doneOf(firstHandle):
+			fmt.Println("第一个任务池执行完成！")
+			firstHandle = nil
+		case <-doneOf(secondHandle):
+			fmt.Println("第二个任务池已被取消！")
+			secondHandle = nil
+		}
+	}
+	if !first.IsAllDone() || !second.IsInterrupt() {
+		t.Error("第一个任务池应当执行完成，第二个任务池应当被中断！")
+	}
+}
+
+// 返回任务池句柄的结束通道，句柄为nil时返回nil通道
+func doneOf(handle *TaskHandle) <-chan struct{} {
+	if handle == nil {
+		return nil
+	}
+	return handle.Done()
+}
+
+// 测试无返回值的并发任务池-任务执行超时以及任务池截止时间
+func TestTaskPool_Timeout(t *testing.T) {
+	// 卡住的任务同时执行的数量，以及其它任务全部完成的时间
+	var stuck, stuckPeak, othersDone int32
+	var othersElapsed time.Duration
+	start := time.Now()
+	// 1.创建任务池
+	pool := NewErrorTaskPool[*DownloadTask](3, 0, 0, createTaskList()[:6],
+		// 每个任务的自定义执行逻辑回调函数
+		func(ctx context.Context, task *DownloadTask, pool *TaskPool[*DownloadTask]) error {
+			// 模拟一个不响应上下文取消、一直卡住的任务
+			if task.Filename == "file-1.txt" {
+				updatePeak(&stuckPeak, atomic.AddInt32(&stuck, 1))
+				time.Sleep(700 * time.Millisecond)
+				atomic.AddInt32(&stuck, -1)
+				return nil
+			}
+			select {
+			case <-time.After(100 * time.Millisecond):
+				if atomic.AddInt32(&othersDone, 1) == 5 {
+					othersElapsed = time.Since(start)
+				}
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil, nil)
+	// 2.设定每个任务的超时时间，超时的任务最多执行2次
+	pool.SetTaskTimeout(300 * time.Millisecond)
+	pool.SetRetryPolicy(NewFixedRetryPolicy(2, 0))
+	// 3.启动任务池，卡住的任务不会一直占用worker，但是会在其回调函数返回之后才被重试
+	e := pool.Start()
+	fmt.Println(e)
+	if !errors.Is(e, ErrTaskTimeout) || len(pool.GetDeadTaskList()) != 1 || othersElapsed > 700*time.Millisecond {
+		t.Error("卡住的任务应当在超时重试后失败，且不会一直占用worker！")
+	}
+	if atomic.LoadInt32(&stuckPeak) != 1 {
+		t.Error("超时的任务在回调函数返回之前不应当被重试！")
+	}
+	// 4.设定了截止时间的任务池，到达截止时间后会被中断
+	pool = NewSimpleTaskPool[*DownloadTask](1, createTaskList(), func(task *DownloadTask, pool *TaskPool[*DownloadTask]) {
+		time.Sleep(100 * time.Millisecond)
+	})
+	pool.SetDeadline(time.Now().Add(250 * time.Millisecond))
+	_ = pool.Start()
+	if !pool.IsInterrupt() || len(pool.GetQueuedTaskList()) == 0 {
+		t.Error("到达截止时间后任务池应当被中断！")
+	}
+}
+
+// 测试无返回值的并发任务池-优先级队列以及保存和恢复任务的优先级
+func TestTaskPool_PriorityQueue(t *testing.T) {
+	// 1.创建任务池，只有一个worker，以便观察执行顺序
+	executed := make([]int, 0)
+	pool := NewSimpleTaskPool[int](1, []int{1, 2, 3, 4, 5, 6}, func(task int, pool *TaskPool[int]) {
+		executed = append(executed, task)
+	})
+	// 2.启用优先级队列，偶数任务优先执行
+	pool.EnablePriorityQueue(func(task int) int {
+		return 1 - task%2
+	})
+	// 3.以更高的优先级提交一个紧急任务
+	_ = pool.SubmitWithPriority(100, 10)
+	// 4.保存任务，并在另一个任务池中恢复
+	file := filepath.Join(t.TempDir(), "tasks.json")
+	if e := pool.SaveTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	restored := NewSimpleTaskPool[int](1, nil, func(task int, pool *TaskPool[int]) {})
+	restored.EnablePriorityQueue(nil)
+	if e := restored.RestoreTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	fmt.Printf("恢复的任务：%v\n", restored.GetQueuedTaskList())
+	// 5.启动任务池
+	_ = pool.Start()
+	fmt.Printf("执行顺序：%v\n", executed)
+	if fmt.Sprint(executed) != "[100 2 4 6 1 3 5]" || fmt.Sprint(restored.GetQueuedTaskList()) != fmt.Sprint(executed) {
+		t.Error("任务应当按照优先级执行，优先级相同的任务按照提交顺序执行！")
+	}
+}
+
+// 原子地更新观测到的最大值
+func updatePeak(peak *int32, current int32) {
+	for {
+		old := atomic.LoadInt32(peak)
+		if current <= old || atomic.CompareAndSwapInt32(peak, old, current) {
+			return
+		}
+	}
+}
+
+// 测试无返回值的并发任务池-运行期间调整任务并发数
+func TestTaskPool_SetConcurrency(t *testing.T) {
+	// 正在执行的任务数，以及调整并发数之后的最大同时执行任务数
+	var running, peak int32
+	// 1.创建任务池
+	pool := NewSimpleTaskPool[int](2, make([]int, 60), func(task int, pool *TaskPool[int]) {
+		updatePeak(&peak, atomic.AddInt32(&running, 1))
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+	handle := pool.StartAsync()
+	// 2.运行期间增加并发数
+	time.Sleep(100 * time.Millisecond)
+	pool.SetConcurrency(6)
+	time.Sleep(100 * time.Millisecond)
+	if atomic.LoadInt32(&peak) != 6 {
+		t.Errorf("增加并发数后，最大同时执行任务数应当为6，实际为%d", atomic.LoadInt32(&peak))
+	}
+	// 3.减少并发数，多出的worker执行完当前任务后退出
+	pool.SetConcurrency(1)
+	time.Sleep(50 * time.Millisecond)
+	atomic.StoreInt32(&peak, 0)
+	_ = handle.Wait()
+	fmt.Printf("减少并发数后，最大同时执行任务数：%d\n", atomic.LoadInt32(&peak))
+	if atomic.LoadInt32(&peak) != 1 || !pool.IsAllDone() || pool.GetConcurrency() != 1 {
+		t.Error("减少并发数后，应当只有一个任务同时执行！")
+	}
+}
+
+// 测试无返回值的并发任务池-暂停和恢复任务池
+func TestTaskPool_Pause(t *testing.T) {
+	// 已执行完成的任务数，以及lookup回调函数被调用的次数
+	var executed, lookupCount int32
+	// 1.创建任务池
+	pool := NewTaskPool[int](3, 0, 0, make([]int, 30), func(task int, pool *TaskPool[int]) {
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&executed, 1)
+	}, nil, func(pool *TaskPool[int]) {
+		atomic.AddInt32(&lookupCount, 1)
+	})
+	pool.SetLookupInterval(10 * time.Millisecond)
+	handle := pool.StartAsync()
+	// 2.暂停任务池，等待正在执行的任务完成
+	time.Sleep(50 * time.Millisecond)
+	pool.Pause()
+	time.Sleep(50 * time.Millisecond)
+	pausedExecuted, pausedLookup := atomic.LoadInt32(&executed), atomic.LoadInt32(&lookupCount)
+	time.Sleep(100 * time.Millisecond)
+	fmt.Printf("暂停期间已完成的任务数：%d，剩余任务数：%d\n", pausedExecuted, len(pool.GetQueuedTaskList()))
+	if !pool.IsPaused() || atomic.LoadInt32(&executed) != pausedExecuted || atomic.LoadInt32(&lookupCount) == pausedLookup {
+		t.Error("暂停期间不应当执行新的任务，但lookup回调函数应当继续被调用！")
+	}
+	// 3.恢复任务池
+	pool.Resume()
+	_ = handle.Wait()
+	if atomic.LoadInt32(&executed) != 30 || pool.IsPaused() {
+		t.Error("恢复任务池后全部任务应当执行完成！")
+	}
+}
+
+// 测试无返回值的并发任务池-速率限制
+func TestTaskPool_RateLimit(t *testing.T) {
+	// 1.创建任务池，任务并发数不会影响速率限制
+	pool := NewSimpleTaskPool[int](8, make([]int, 11), func(task int, pool *TaskPool[int]) {})
+	// 2.每秒最多执行20个任务，第一个任务可以立即执行
+	pool.SetRateLimit(20, 1)
+	start := time.Now()
+	_ = pool.Start()
+	elapsed := time.Since(start)
+	fmt.Printf("限制速率后执行耗时：%s\n", elapsed)
+	if elapsed < 450*time.Millisecond {
+		t.Error("任务池的执行速率应当被限制！")
+	}
+	// 3.按照键分别限制速率，两个键的任务互不影响
+	list := make([]int, 0)
+	for i := 0; i < 22; i++ {
+		list = append(list, i)
+	}
+	pool = NewSimpleTaskPool[int](8, list, func(task int, pool *TaskPool[int]) {})
+	pool.SetKeyedRateLimit(20, 1, func(task int) string {
+		return fmt.Sprintf("host-%d", task%2)
+	})
+	start = time.Now()
+	_ = pool.Start()
+	elapsed = time.Since(start)
+	fmt.Printf("按键限制速率后执行耗时：%s\n", elapsed)
+	if elapsed < 450*time.Millisecond || elapsed > 900*time.Millisecond {
+		t.Error("每个键的执行速率应当被分别限制！")
+	}
+	// 4.一个键的令牌不足时，worker会先执行其它键的任务
+	list = []int{0, 2, 4, 6, 1, 3, 5, 7}
+	var lock sync.Mutex
+	finished := make(map[int]time.Duration)
+	pool = NewSimpleTaskPool[int](2, list, func(task int, pool *TaskPool[int]) {
+		lock.Lock()
+		finished[task%2] = time.Since(start)
+		lock.Unlock()
+	})
+	pool.SetKeyedRateLimit(4, 1, func(task int) string {
+		return fmt.Sprintf("host-%d", task%2)
+	})
+	start = time.Now()
+	_ = pool.Start()
+	fmt.Printf("每个键的任务执行完成耗时：%v\n", finished)
+	if finished[1] > 1200*time.Millisecond {
+		t.Error("一个键的速率限制不应当影响其它键的任务！")
+	}
+}
+
+// 测试无返回值的并发任务池-自适应并发数
+func TestTaskPool_AdaptiveConcurrency(t *testing.T) {
+	// lookup回调函数中观测到的最大并发数
+	var peak int32
+	createPool := func(fail bool) *TaskPool[int] {
+		pool := NewErrorTaskPool[int](4, 0, 0, make([]int, 300),
+			func(ctx context.Context, task int, pool *TaskPool[int]) error {
+				time.Sleep(5 * time.Millisecond)
+				if fail {
+					return errors.New("上游服务器繁忙")
+				}
+				return nil
+			}, nil,
+			// 在lookup中读取当前的并发数
+			func(pool *TaskPool[int]) {
+				if concurrent := int32(pool.GetConcurrency()); concurrent > atomic.LoadInt32(&peak) {
+					atomic.StoreInt32(&peak, concurrent)
+				}
+			})
+		controller := NewAdaptiveConcurrency(1, 8)
+		controller.Window = 20 * time.Millisecond
+		pool.SetAdaptiveConcurrency(controller)
+		pool.SetLookupInterval(5 * time.Millisecond)
+		return pool
+	}
+	// 1.任务执行正常时，并发数逐渐增加
+	pool := createPool(false)
+	_ = pool.Start()
+	fmt.Printf("任务执行正常时的最大并发数：%d\n", atomic.LoadInt32(&peak))
+	if atomic.LoadInt32(&peak) <= 4 {
+		t.Error("任务执行正常时，并发数应当增加！")
+	}
+	// 2.任务一直失败时，并发数减少至最小值
+	pool = createPool(true)
+	_ = pool.Start()
+	fmt.Printf("任务一直失败时的并发数：%d\n", pool.GetConcurrency())
+	if pool.GetConcurrency() != 1 {
+		t.Error("任务一直失败时，并发数应当减少至最小值！")
+	}
+}
+
+// 带有键的任务
+type keyedTask struct {
+	// 任务的键，例如要写入的文件名
+	Key string
+	// 任务的提交顺序
+	Seq int
+}
+
+// 测试无返回值的并发任务池-按键串行执行
+func TestTaskPool_KeyedSerialization(t *testing.T) {
+	// 1.创建任务列表，3个键交替出现
+	list := make([]keyedTask, 0)
+	for i := 0; i < 30; i++ {
+		list = append(list, keyedTask{Key: fmt.Sprintf("file-%d", i%3), Seq: i})
+	}
+	// 每个键正在执行的任务数、每个键的执行顺序，以及全局最大同时执行任务数
+	var lock sync.Mutex
+	runningByKey := make(map[string]int)
+	orderByKey := make(map[string][]int)
+	var running, peak int32
+	var queuedCounts map[string]int
+	// 2.创建任务池
+	pool := NewTaskPool[keyedTask](6, 0, 0, list, func(task keyedTask, pool *TaskPool[keyedTask]) {
+		lock.Lock()
+		runningByKey[task.Key]++
+		if runningByKey[task.Key] > 1 {
+			t.Errorf("键%s的任务被同时执行！", task.Key)
+		}
+		orderByKey[task.Key] = append(orderByKey[task.Key], task.Seq)
+		lock.Unlock()
+		updatePeak(&peak, atomic.AddInt32(&running, 1))
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		lock.Lock()
+		runningByKey[task.Key]--
+		lock.Unlock()
+	}, nil, func(pool *TaskPool[keyedTask]) {
+		if queuedCounts == nil {
+			queuedCounts = pool.GetQueuedTaskCountByKey()
+		}
+	})
+	// 3.启用按键串行执行
+	pool.EnableKeyedSerialization(func(task keyedTask) string {
+		return task.Key
+	})
+	pool.SetLookupInterval(5 * time.Millisecond)
+	_ = pool.Start()
+	fmt.Printf("每个键的执行顺序：%v，排队任务数：%v\n", orderByKey, queuedCounts)
+	for key, order := range orderByKey {
+		if !sort.IntsAreSorted(order) || len(order) != 10 {
+			t.Errorf("键%s的任务应当按照提交顺序执行！", key)
+		}
+	}
+	if atomic.LoadInt32(&peak) != 3 || len(queuedCounts) != 3 {
+		t.Error("不同键的任务应当并发执行！")
+	}
+}
+
+// 测试无返回值的并发任务池-按键串行执行的任务超时
+func TestTaskPool_KeyedSerializationTimeout(t *testing.T) {
+	// 1.创建任务池，任务执行时不响应上下文的取消，执行时间超过超时时间
+	list := []keyedTask{{Key: "file", Seq: 0}, {Key: "file", Seq: 1}, {Key: "file", Seq: 2}}
+	var running, peak int32
+	pool := NewSimpleTaskPool[keyedTask](3, list, func(task keyedTask, pool *TaskPool[keyedTask]) {
+		updatePeak(&peak, atomic.AddInt32(&running, 1))
+		time.Sleep(150 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+	pool.SetTaskTimeout(50 * time.Millisecond)
+	pool.EnableKeyedSerialization(func(task keyedTask) string {
+		return task.Key
+	})
+	// 2.超时的任务在回调函数返回之前仍然持有键，同键的任务不会被同时执行
+	_ = pool.Start()
+	time.Sleep(200 * time.Millisecond)
+	if atomic.LoadInt32(&peak) != 1 {
+		t.Errorf("超时的任务返回之前，同键的任务不应当被执行，最大同时执行数：%d", peak)
+	}
+}
+
+// 测试无返回值的并发任务池-按分类限制并发数
+func TestTaskPool_CategoryLimits(t *testing.T) {
+	// 1.创建任务列表，前10个为耗时较长的视频任务，后20个为缩略图任务
+	list := make([]keyedTask, 0)
+	for i := 0; i < 30; i++ {
+		category := "thumbnail"
+		if i < 10 {
+			category = "video"
+		}
+		list = append(list, keyedTask{Key: category, Seq: i})
+	}
+	// 正在执行的视频任务数以及其最大值，最后一个缩略图任务完成的时间
+	var runningVideo, peakVideo int32
+	var thumbnailDone int64
+	var videoStats *CategoryStats
+	start := time.Now()
+	// 2.创建任务池
+	pool := NewTaskPool[keyedTask](4, 0, 0, list, func(task keyedTask, pool *TaskPool[keyedTask]) {
+		if task.Key == "thumbnail" {
+			time.Sleep(5 * time.Millisecond)
+			atomic.StoreInt64(&thumbnailDone, int64(time.Since(start)))
+			return
+		}
+		updatePeak(&peakVideo, atomic.AddInt32(&runningVideo, 1))
+		time.Sleep(100 * time.Millisecond)
+		atomic.AddInt32(&runningVideo, -1)
+	}, nil, func(pool *TaskPool[keyedTask]) {
+		// 在lookup中查看每个分类的执行状态
+		if stats := pool.GetCategoryStats()["video"]; stats != nil && videoStats == nil && stats.Running > 0 {
+			videoStats = stats
+		}
+	})
+	// 3.视频任务最多同时执行2个，缩略图任务不限制
+	pool.SetCategoryLimits(func(task keyedTask) string {
+		return task.Key
+	}, map[string]int{"video": 2})
+	pool.SetLookupInterval(5 * time.Millisecond)
+	_ = pool.Start()
+	fmt.Printf("视频任务状态：%+v，缩略图任务完成耗时：%s，总耗时：%s\n", videoStats, time.Duration(atomic.LoadInt64(&thumbnailDone)), time.Since(start))
+	if atomic.LoadInt32(&peakVideo) != 2 || videoStats == nil || videoStats.Running != 2 || videoStats.Limit != 2 {
+		t.Error("视频任务最多同时执行2个！")
+	}
+	if time.Duration(atomic.LoadInt64(&thumbnailDone)) > 200*time.Millisecond {
+		t.Error("缩略图任务不应当被视频任务阻塞！")
+	}
+	// 4.已取得分类名额但还在等待执行延迟的任务同样视为该分类正在执行的任务
+	pool = NewTaskPool[keyedTask](4, 0, 200*time.Millisecond, list[:6], func(task keyedTask, pool *TaskPool[keyedTask]) {}, nil, nil)
+	pool.SetCategoryLimits(func(task keyedTask) string {
+		return task.Key
+	}, map[string]int{"video": 2})
+	handle := pool.StartAsync()
+	time.Sleep(100 * time.Millisecond)
+	videoStats = pool.GetCategoryStats()["video"]
+	fmt.Printf("等待执行延迟时的视频任务状态：%+v\n", videoStats)
+	if videoStats == nil || videoStats.Running != 2 || videoStats.Queued != 4 {
+		t.Error("占用分类名额的任务应当视为正在执行的任务！")
+	}
+	_ = handle.Wait()
+}
+
+// 测试无返回值的并发任务池-权重模式
+func TestTaskPool_WeightedCapacity(t *testing.T) {
+	// 1.创建任务列表，每4个任务中有一个重任务，最后一个任务的权重超过容量
+	list := make([]int, 0)
+	for i := 1; i <= 40; i++ {
+		list = append(list, i)
+	}
+	weightOf := func(task int) int {
+		if task == 40 {
+			return 10
+		}
+		if task%4 == 0 {
+			return 3
+		}
+		return 1
+	}
+	// 正在执行的任务的权重之和及其最大值，以及正在执行的任务数
+	var inFlight, peak, running int32
+	var overError int32
+	// 2.创建任务池，容量为4
+	pool := NewSimpleTaskPool[int](8, list, func(task int, pool *TaskPool[int]) {
+		current := atomic.AddInt32(&inFlight, int32(weightOf(task)))
+		updatePeak(&peak, current)
+		if atomic.AddInt32(&running, 1) > 1 && weightOf(task) == 10 || weightOf(task) < 10 && current > 4 {
+			atomic.StoreInt32(&overError, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&inFlight, -int32(weightOf(task)))
+	})
+	pool.SetWeightedCapacity(4, weightOf)
+	_ = pool.Start()
+	fmt.Printf("正在执行的任务的最大权重之和：%d\n", atomic.LoadInt32(&peak))
+	// 3.超过容量的任务单独执行，其余时刻权重之和不超过容量
+	if atomic.LoadInt32(&peak) != 10 || atomic.LoadInt32(&overError) != 0 || pool.GetInFlightWeight() != 0 {
+		t.Error("正在执行的任务的权重之和不应当超过容量，且超过容量的任务应当单独执行！")
+	}
+}
+
+// 带有依赖关系的任务
+type stageTask struct {
+	// 任务名称，即任务ID
+	Name string `json:"name"`
+	// 依赖的任务名称，多个依赖使用逗号分隔
+	Deps string `json:"deps"`
+}
+
+// ID 返回任务ID
+func (task stageTask) ID() string {
+	return task.Name
+}
+
+// Dependencies 返回依赖的任务ID
+func (task stageTask) Dependencies() []string {
+	if task.Deps == "" {
+		return nil
+	}
+	return strings.Split(task.Deps, ",")
+}
+
+// 测试无返回值的并发任务池-任务依赖关系
+func TestTaskPool_Dependencies(t *testing.T) {
+	// 1.创建任务列表：下载 -> 解压 -> 索引 -> 汇总，任务按照相反的顺序提交
+	list := []stageTask{{Name: "report", Deps: "index-0,index-1,index-2"}}
+	for i := 0; i < 3; i++ {
+		list = append(list,
+			stageTask{Name: fmt.Sprintf("index-%d", i), Deps: fmt.Sprintf("unpack-%d", i)},
+			stageTask{Name: fmt.Sprintf("unpack-%d", i), Deps: fmt.Sprintf("download-%d", i)},
+			stageTask{Name: fmt.Sprintf("download-%d", i)})
+	}
+	// 已执行完成的任务
+	var lock sync.Mutex
+	finished := make(map[string]bool)
+	run := func(ctx context.Context, task stageTask, pool *TaskPool[stageTask]) error {
+		lock.Lock()
+		defer lock.Unlock()
+		for _, dependency := range task.Dependencies() {
+			if !finished[dependency] {
+				t.Errorf("任务%s在依赖%s完成之前被执行！", task.Name, dependency)
+			}
+		}
+		// 模拟第2个文件下载失败
+		if task.Name == "download-1" {
+			return errors.New("下载失败")
+		}
+		finished[task.Name] = true
+		return nil
+	}
+	// 2.启用依赖关系，任务对象实现了DependentTask接口
+	pool := NewErrorTaskPool[stageTask](4, 0, 0, list, run, nil, nil)
+	pool.EnableDependencies(nil, nil)
+	e := pool.Start()
+	fmt.Println(e)
+	// 3.下载失败的文件的后续任务以及汇总任务都不会被执行
+	if !errors.Is(e, ErrDependencyFailed) || len(pool.GetFailedTaskList()) != 4 || len(finished) != 6 || pool.Stats().Queued != 0 {
+		t.Error("依赖执行失败的任务应当被记录为失败！")
+	}
+	// 4.存在循环依赖时任务池不会启动
+	pool = NewErrorTaskPool[stageTask](4, 0, 0, []stageTask{{Name: "a", Deps: "b"}, {Name: "b", Deps: "a"}}, run, nil, nil)
+	pool.EnableDependencies(nil, nil)
+	e = pool.Start()
+	fmt.Println(e)
+	if !errors.Is(e, ErrDependencyCycle) {
+		t.Error("应当检测到循环依赖！")
+	}
+}
+
+// 测试无返回值的并发任务池-保存并恢复执行到一半的依赖关系
+func TestTaskPool_DependenciesRestore(t *testing.T) {
+	list := []stageTask{{Name: "download"}, {Name: "unpack", Deps: "download"}, {Name: "index", Deps: "unpack"}}
+	file := filepath.Join(t.TempDir(), "tasks.json")
+	// 1.在解压完成时中断任务池，并保存任务
+	pool := NewSimpleTaskPool[stageTask](2, list, func(task stageTask, pool *TaskPool[stageTask]) {
+		if task.Name == "unpack" {
+			pool.Interrupt()
+		}
+	})
+	pool.EnableDependencies(nil, nil)
+	_ = pool.Start()
+	if e := pool.SaveTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	// 2.从文件恢复任务，已经完成的依赖不会被重新执行
+	executed := make([]string, 0)
+	restored := NewSimpleTaskPool[stageTask](2, nil, func(task stageTask, pool *TaskPool[stageTask]) {
+		executed = append(executed, task.Name)
+	})
+	restored.EnableDependencies(nil, nil)
+	if e := restored.RestoreTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	if e := restored.Start(); e != nil || fmt.Sprint(executed) != "[index]" {
+		t.Errorf("恢复后应当只执行剩余的任务，实际执行：%v，错误：%v", executed, e)
+	}
+}
+
+// 包含不可比较字段的任务
+type requestTask struct {
+	ID      string
+	Headers map[string]string
+	Body    []byte
+}
+
+// 测试无返回值的并发任务池-不可比较的任务对象以及任务ID
+func TestTaskPool_TaskID(t *testing.T) {
+	list := make([]requestTask, 0)
+	for i := 0; i < 6; i++ {
+		list = append(list, requestTask{ID: fmt.Sprintf("req-%d", i%3), Headers: map[string]string{"index": fmt.Sprint(i)}, Body: []byte("hello")})
+	}
+	// 1.不可比较的任务对象可以直接使用，不指定任务ID时不会被去重
+	var executed int32
+	pool := NewSimpleTaskPool[requestTask](2, list, func(task requestTask, pool *TaskPool[requestTask]) {
+		atomic.AddInt32(&executed, 1)
+		time.Sleep(20 * time.Millisecond)
+	})
+	if len(pool.GetAllTaskList()) != 6 {
+		t.Error("未指定任务ID时不可比较的任务不应当被去重！")
+	}
+	_ = pool.Start()
+	if executed != 6 {
+		t.Errorf("全部任务都应当被执行，实际执行：%d", executed)
+	}
+	// 2.指定任务ID后，ID相同的任务在保存时会被去重
+	file := filepath.Join(t.TempDir(), "tasks.json")
+	pool = NewSimpleTaskPool[requestTask](2, list, func(task requestTask, pool *TaskPool[requestTask]) {
+		pool.Interrupt()
+	})
+	pool.SetTaskID(func(task requestTask) string {
+		return task.ID
+	})
+	_ = pool.Start()
+	if e := pool.SaveTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	saved, e := LoadTaskFile[requestTask](file)
+	if e != nil || len(saved) != 3 {
+		t.Errorf("保存的任务应当按照任务ID去重，实际保存：%d", len(saved))
+	}
+}
+
+// 测试无返回值的并发任务池-值相同的任务
+func TestTaskPool_DuplicateTasks(t *testing.T) {
+	// 1.任务列表中包含多个值相同的任务，值相同的任务会同时执行
+	list := []string{"a", "a", "a", "b"}
+	var started sync.WaitGroup
+	started.Add(3)
+	release := make(chan struct{})
+	pool := NewSimpleTaskPool[string](4, list, func(task string, pool *TaskPool[string]) {
+		if task == "a" {
+			started.Done()
+			<-release
+		}
+	})
+	handle := pool.StartAsync()
+	started.Wait()
+	// 2.值相同的任务会被分别记录，其中一个执行完成不会影响其余任务的状态
+	time.Sleep(50 * time.Millisecond)
+	if len(pool.GetRunningTaskList()) != 3 || pool.IsAllDone() {
+		t.Errorf("值相同的任务应当被分别记录，实际正在执行：%v", pool.GetRunningTaskList())
+	}
+	// 3.中断任务池后保存任务，值相同的任务均会被保存
+	pool.Interrupt()
+	file := filepath.Join(t.TempDir(), "tasks.json")
+	if e := pool.SaveTaskList(file); e != nil {
+		t.Fatal(e)
+	}
+	close(release)
+	_ = handle.Wait()
+	saved, _ := LoadTaskFile[string](file)
+	if len(saved) != 3 {
+		t.Errorf("值相同的任务均应当被保存，实际保存：%v", saved)
+	}
+}
+
+// 记录任务事件的监听器，仅实现了部分事件
+type countingListener struct {
+	BaseTaskListener[int]
+	// 锁
+	lock sync.Mutex
+	// 每种事件发生的次数
+	counts map[string]int
+	// 出现过的worker的ID
+	workers map[int]bool
+	// 任务池运行结束时的错误
+	doneError error
+}
+
+// 记录一次事件，并记录执行任务的worker的ID
+func (listener *countingListener) record(name string, event *TaskEvent[int]) {
+	listener.lock.Lock()
+	defer listener.lock.Unlock()
+	listener.counts[name]++
+	if event != nil && event.WorkerID != 0 {
+		listener.workers[event.WorkerID] = true
+	}
+}
+
+// OnQueued 记录任务入队事件
+func (listener *countingListener) OnQueued(event *TaskEvent[int]) {
+	listener.record("queued", event)
+}
+
+// OnStart 记录任务开始执行事件
+func (listener *countingListener) OnStart(event *TaskEvent[int]) {
+	listener.record("start", event)
+}
+
+// OnSuccess 记录任务执行成功事件
+func (listener *countingListener) OnSuccess(event *TaskEvent[int]) {
+	listener.record("success", event)
+}
+
+// OnFailure 记录任务最终执行失败事件
+func (listener *countingListener) OnFailure(event *TaskEvent[int]) {
+	fmt.Printf("任务%d在第%d次执行时失败：%s\n", event.Task, event.Attempt, event.Err)
+	listener.record("failure", event)
+}
+
+// OnRetry 记录任务重试事件
+func (listener *countingListener) OnRetry(event *TaskEvent[int]) {
+	listener.record("retry", event)
+}
+
+// OnPoolDone 记录任务池运行结束事件以及返回的错误
+func (listener *countingListener) OnPoolDone(e error) {
+	listener.doneError = e
+	listener.record("done", nil)
+}
+
+// 测试无返回值的并发任务池-任务事件监听器
+func TestTaskPool_Listener(t *testing.T) {
+	// 1.创建任务池，任务3总是执行失败，并会被重试1次
+	pool := NewErrorTaskPool[int](2, 0, 0, []int{1, 2, 3, 4}, func(ctx context.Context, task int, pool *TaskPool[int]) error {
+		if task == 3 {
+			return errors.New("执行失败")
+		}
+		return nil
+	}, nil, nil)
+	pool.SetRetryPolicy(NewFixedRetryPolicy(2, 0))
+	listener := &countingListener{counts: make(map[string]int), workers: make(map[int]bool)}
+	pool.AddListener(listener)
+	// 2.启动前提交的任务同样会触发入队事件
+	_ = pool.Submit(5)
+	e := pool.Start()
+	fmt.Println(listener.counts)
+	expected := map[string]int{"queued": 5, "start": 6, "success": 4, "retry": 1, "failure": 1, "done": 1}
+	if fmt.Sprint(listener.counts) != fmt.Sprint(expected) || listener.doneError != e || e == nil {
+		t.Errorf("任务事件的次数不正确：%v", listener.counts)
+	}
+	for id := range listener.workers {
+		if id < 1 || id > 2 {
+			t.Errorf("worker的ID不正确：%d", id)
+		}
+	}
+}
+
+// 测试无返回值的并发任务池-运行指标
+func TestTaskPool_Stats(t *testing.T) {
+	// 1.创建任务池，任务0总是执行失败，并会在10ms后被重试1次，其余任务执行耗时为任务值毫秒
+	list := []int{0, 10, 10, 10, 10, 10, 10, 10, 10, 50}
+	pool := NewErrorTaskPool[int](3, 0, 0, list, func(ctx context.Context, task int, pool *TaskPool[int]) error {
+		if task == 0 {
+			return errors.New("执行失败")
+		}
+		time.Sleep(time.Duration(task) * time.Millisecond)
+		return nil
+	}, nil, nil)
+	pool.SetRetryPolicy(NewFixedRetryPolicy(2, 10*time.Millisecond))
+	_ = pool.Submit(10)
+	// 2.运行结束后获取运行指标
+	_ = pool.Start()
+	stats := pool.Stats()
+	fmt.Printf("%+v\n", stats)
+	if stats.Submitted != 11 || stats.Succeeded != 10 || stats.Failed != 1 || stats.Retried != 1 || stats.Running != 0 || stats.Queued != 0 {
+		t.Error("任务数量统计不正确！")
+	}
+	if stats.Latency.Count != 12 || stats.Latency.P50 < 10*time.Millisecond || stats.Latency.P99 < stats.Latency.P50 || stats.Latency.Max < 50*time.Millisecond {
+		t.Error("执行耗时统计不正确！")
+	}
+	if stats.Throughput1s <= 0 || stats.Throughput1m <= 0 {
+		t.Error("吞吐量统计不正确！")
+	}
+	// 3.等待执行延迟的任务视为排队中的任务，而不是正在执行的任务
+	var executing int32
+	pool = NewTaskPool[int](4, 0, 200*time.Millisecond, make([]int, 8), func(task int, pool *TaskPool[int]) {
+		atomic.AddInt32(&executing, 1)
+	}, nil, nil)
+	handle := pool.StartAsync()
+	time.Sleep(100 * time.Millisecond)
+	stats = pool.Stats()
+	if atomic.LoadInt32(&executing) != 0 || stats.Running != 0 || len(pool.GetRunningTaskList()) != 0 || stats.Queued != 8 || len(pool.GetQueuedTaskList()) != 8 {
+		t.Errorf("等待执行延迟的任务不应当视为正在执行的任务：%+v", stats)
+	}
+	_ = handle.Wait()
+	// 4.吞吐量按照完整的秒统计，不会随当前秒的进度波动
+	counter := &throughputCounter{}
+	start := time.Unix(1000, 0)
+	counter.reset(start)
+	for i := 0; i < 300; i++ {
+		counter.record(start.Add(time.Duration(i) * 10 * time.Millisecond))
+	}
+	for _, offset := range []time.Duration{3100 * time.Millisecond, 3500 * time.Millisecond, 3900 * time.Millisecond} {
+		now := start.Add(offset)
+		if rate1s, rate10s := counter.rate(now, 1), counter.rate(now, 10); rate1s != 100 || rate10s != 100 {
+			t.Errorf("稳定的吞吐量统计不正确：%.2f，%.2f", rate1s, rate10s)
+		}
+	}
+}
+
+// 测试无返回值的并发任务池-任务池结束后重试任务
+func TestTaskPool_RetryAfterDone(t *testing.T) {
+	// 1.任务池结束后重试的任务不会被放回任务队列，而是被记录为失败
+	pool := NewSimpleTaskPool[int](2, []int{1, 2}, func(task int, pool *TaskPool[int]) {})
+	_ = pool.Start()
+	pool.Retry(3)
+	pool.RetryWithPriority(4, 1)
+	failures := pool.GetFailureList()
+	if !pool.IsAllDone() || len(pool.GetAllTaskList()) != 0 || len(failures) != 2 || !errors.Is(failures[0].Err, ErrPoolClosed) {
+		t.Error("任务池结束后重试的任务应当被记录为失败！")
+	}
+	// 2.已被关闭但还在执行剩余任务的开放模式任务池，仍然会接收重试的任务
+	var runs int64
+	openPool := NewSimpleTaskPool[int](1, nil, func(task int, pool *TaskPool[int]) {
+		if atomic.AddInt64(&runs, 1) == 1 {
+			time.Sleep(50 * time.Millisecond)
+			pool.Retry(task)
+		}
+	})
+	openPool.Open()
+	handle := openPool.StartAsync()
+	_ = openPool.Submit(1)
+	openPool.Close()
+	e := handle.Wait()
+	fmt.Println("执行次数：", atomic.LoadInt64(&runs), e)
+	if e != nil || atomic.LoadInt64(&runs) != 2 || len(openPool.GetFailedTaskList()) != 0 {
+		t.Error("任务池关闭后仍应当执行重试的任务！")
+	}
 }
\ No newline at end of file
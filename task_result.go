@@ -0,0 +1,43 @@
+package concurrent_task_pool
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// TaskResult 是有返回值的任务池中一个任务的执行结果，关联了任务对象及其返回值
+type TaskResult[T, R any] struct {
+	// 产生该结果的任务对象
+	Task T
+	// 任务的提交序号，初始任务列表中任务的序号即为其下标，之后通过 Submit 等方法提交的任务序号依次递增
+	Index int
+	// 任务执行的返回值，任务执行失败时为零值
+	Value R
+	// 任务执行失败时的错误，成功时为nil
+	Err error
+	// 得到该结果时，任务已经被执行的次数
+	Attempts int
+	// 最后一次执行任务的开始时间
+	StartTime time.Time
+	// 最后一次执行任务的耗时
+	Duration time.Duration
+}
+
+// 将任务结果按照任务的提交序号排序，序号相同的结果保持原有顺序
+//
+//   - results 要排序的任务结果切片，会被原地排序
+func sortResultsByIndex[T, R any](results []*TaskResult[T, R]) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+}
+
+// 判断任务的返回值是否为对应类型的零值，返回值的类型可以是不可比较的类型
+//
+//   - value 任务的返回值
+//
+// 返回值为nil或者对应类型的零值时返回true
+func isZeroValue[R any](value R) bool {
+	return reflect.ValueOf(&value).Elem().IsZero()
+}
\ No newline at end of file
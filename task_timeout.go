@@ -0,0 +1,108 @@
+package concurrent_task_pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutTask 是可以自定义执行超时时间的任务
+// 若任务对象实现了该接口，且 Timeout 方法返回值大于0，则会覆盖任务池通过 SetTaskTimeout 设定的默认超时时间
+type TimeoutTask interface {
+	// Timeout 返回该任务单次执行的超时时间，小于等于0时使用任务池的默认超时时间
+	Timeout() time.Duration
+}
+
+// 一次任务执行的结果
+type taskOutcome[R any] struct {
+	// 任务执行的返回值
+	result R
+	// 任务执行的错误
+	err error
+}
+
+// 获取一个任务单次执行的超时时间
+//
+//   - task 任务对象
+//
+// 返回超时时间，小于等于0表示不限制
+func (pool *basePool[T]) timeoutOf(task T) time.Duration {
+	if timeoutTask, ok := any(task).(TimeoutTask); ok {
+		if timeout := timeoutTask.Timeout(); timeout > 0 {
+			return timeout
+		}
+	}
+	return pool.taskTimeout
+}
+
+// 执行一次任务，执行过程中发生的panic会被恢复并转换为 *PanicError
+// 若该任务设定了超时时间，则传递给任务的上下文会在超时后被取消，且超时后不再等待任务执行回调函数返回，worker可以继续执行下一个任务
+// 任务执行回调函数真正返回之后才会调用complete处理执行结果，超时的任务的错误为 ErrTaskTimeout
+// 因此在超时的回调函数返回之前，该任务仍然视为正在执行，其占用的名额以及权重不会被释放，也不会被重试
+//
+//   - pool 任务所属的任务池
+//   - task 要执行的任务对象
+//   - run 执行任务的函数，参数为传递给任务执行回调函数的上下文
+//   - complete 处理任务执行结果的函数，参数为任务执行的返回值以及错误
+//     未超时的任务会在当前线程中调用，超时后不再等待的任务会在后台的回调函数返回之后，在计入workerGroup的后台线程中调用
+func executeTask[T, R any](pool *basePool[T], task T, run func(ctx context.Context) (R, error), complete func(result R, e error)) {
+	// 恢复panic的执行函数
+	execute := func(ctx context.Context) *taskOutcome[R] {
+		outcome := &taskOutcome[R]{}
+		outcome.err = pool.safeRun(func() error {
+			var runError error
+			outcome.result, runError = run(ctx)
+			return runError
+		})
+		return outcome
+	}
+	timeout := pool.timeoutOf(task)
+	if timeout <= 0 {
+		outcome := execute(pool.ctx)
+		complete(outcome.result, outcome.err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(pool.ctx, timeout)
+	// 在单独的线程中执行任务，使超时后worker能够不再等待
+	finished := make(chan *taskOutcome[R], 1)
+	go func() {
+		finished <- execute(ctx)
+	}()
+	// 超时的错误
+	var zero R
+	timeoutError := fmt.Errorf("%w：超过%s", ErrTaskTimeout, timeout)
+	// 处理任务执行的结果，因超时而返回错误的任务同样视为超时
+	resolve := func(outcome *taskOutcome[R]) {
+		defer cancel()
+		if outcome.err != nil && ctx.Err() == context.DeadlineExceeded && pool.ctx.Err() == nil {
+			complete(zero, timeoutError)
+			return
+		}
+		complete(outcome.result, outcome.err)
+	}
+	select {
+	case outcome := <-finished:
+		resolve(outcome)
+	case <-ctx.Done():
+		select {
+		case outcome := <-finished:
+			resolve(outcome)
+			return
+		default:
+		}
+		// 任务池被中断时，仍然等待任务执行回调函数返回
+		if pool.ctx.Err() != nil {
+			resolve(<-finished)
+			return
+		}
+		// 不再等待超时的任务，其执行结果在回调函数返回之后处理
+		// 该线程同样计入workerGroup，使等待全部worker退出时也会等待其处理完执行结果，例如流式模式下不会向已关闭的结果通道发送结果
+		pool.workerGroup.Add(1)
+		go func() {
+			defer pool.workerGroup.Done()
+			<-finished
+			defer cancel()
+			complete(zero, timeoutError)
+		}()
+	}
+}
\ No newline at end of file
@@ -0,0 +1,104 @@
+package concurrent_task_pool
+
+import (
+	"context"
+	"sync"
+)
+
+// WeightedTask 是可以自定义权重的任务
+// 启用权重模式且没有指定权重函数时，若任务对象实现了该接口，则会使用 Weight 方法的返回值作为任务的权重
+type WeightedTask interface {
+	// Weight 返回该任务的权重，即执行该任务所占用的容量，例如该任务所需的内存大小
+	Weight() int
+}
+
+// 带权重的信号量，正在执行的任务的权重之和不会超过容量
+// 权重超过容量的任务会在没有其它任务执行时单独执行
+type weightedSemaphore struct {
+	// 容量
+	capacity int
+	// 正在执行的任务的权重之和
+	inFlight int
+	// 锁
+	lock sync.Mutex
+	// 用于等待容量释放的条件变量，基于lock
+	cond *sync.Cond
+}
+
+// 创建带权重的信号量
+//
+//   - capacity 容量
+//
+// 返回信号量对象指针
+func newWeightedSemaphore(capacity int) *weightedSemaphore {
+	semaphore := &weightedSemaphore{
+		capacity: capacity,
+		inFlight: 0,
+		lock:     sync.Mutex{},
+	}
+	semaphore.cond = sync.NewCond(&semaphore.lock)
+	return semaphore
+}
+
+// 阻塞地占用指定的权重，直到剩余容量足够，或者没有其它任务正在执行
+//
+//   - ctx 上下文，被取消时停止等待
+//   - weight 要占用的权重
+//
+// 成功占用时返回nil，上下文被取消时返回上下文的错误
+func (semaphore *weightedSemaphore) acquire(ctx context.Context, weight int) error {
+	semaphore.lock.Lock()
+	defer semaphore.lock.Unlock()
+	if semaphore.fits(weight) {
+		semaphore.inFlight += weight
+		return nil
+	}
+	// 上下文被取消时唤醒等待的线程
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			semaphore.lock.Lock()
+			semaphore.cond.Broadcast()
+			semaphore.lock.Unlock()
+		case <-stop:
+		}
+	}()
+	for !semaphore.fits(weight) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		semaphore.cond.Wait()
+	}
+	semaphore.inFlight += weight
+	return nil
+}
+
+// 判断指定的权重能否被占用，需要在持有锁时调用
+//
+//   - weight 要占用的权重
+//
+// 剩余容量足够，或者没有其它任务正在执行时返回true
+func (semaphore *weightedSemaphore) fits(weight int) bool {
+	return semaphore.inFlight == 0 || semaphore.inFlight+weight <= semaphore.capacity
+}
+
+// 释放占用的权重，并唤醒正在等待的线程
+//
+//   - weight 要释放的权重
+func (semaphore *weightedSemaphore) release(weight int) {
+	semaphore.lock.Lock()
+	defer semaphore.lock.Unlock()
+	semaphore.inFlight -= weight
+	semaphore.cond.Broadcast()
+}
+
+// 获取正在执行的任务的权重之和
+//
+// 返回已占用的权重
+func (semaphore *weightedSemaphore) used() int {
+	semaphore.lock.Lock()
+	defer semaphore.lock.Unlock()
+	return semaphore.inFlight
+}
\ No newline at end of file
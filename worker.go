@@ -1,6 +1,9 @@
 package concurrent_task_pool
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // worker 是任务池中的每一个任务运行器
 //
@@ -8,49 +11,73 @@ import "time"
 //
 // 一个worker持有一个线程，并一直从任务队列（通道）中获取任务并执行
 // 该worker所执行的任务是无返回值的
-type worker[T comparable] struct {
+type worker[T any] struct {
+	// worker的ID，从1开始编号
+	id int
 	// 自定义任务运行的回调函数
-	run func(task T, taskPool *TaskPool[T])
+	run func(ctx context.Context, task T, taskPool *TaskPool[T]) error
 	// 该worker所属的并发任务池对象的引用
 	taskPool *TaskPool[T]
 }
 
 // worker 构造函数
-func newWorker[T comparable](run func(T, *TaskPool[T]), pool *TaskPool[T]) *worker[T] {
+func newWorker[T any](id int, run func(context.Context, T, *TaskPool[T]) error, pool *TaskPool[T]) *worker[T] {
 	return &worker[T]{
+		id:       id,
 		run:      run,
 		taskPool: pool,
 	}
 }
 
 // 启动worker，该函数会在一个单独的线程中启动并运行worker
-// worker在单独的线程运行，会一直从任务队列中获取任务对象，直到isShutdown为true才结束
-//
-// isShutdown 指示全部任务是否结束的指针，当为true时，worker会在执行完当前任务后立即结束
-func (worker *worker[T]) start(isShutdown *bool) {
+// worker在单独的线程运行，会一直从任务队列中获取任务对象，任务队列为空时阻塞等待，直到任务池结束全部worker，或者任务并发数减少时才结束
+func (worker *worker[T]) start() {
 	// 当前任务池
 	pool := worker.taskPool
-	// 泛型零值
-	var zero T
-	// 在新的线程中运行任务
+	// 在新的线程中运行任务，worker的数量已在创建时计入workerGroup
 	go func() {
-		// 除非isShutdown为true，否则将会一直尝试从队列取值
-		for !*isShutdown {
+		defer pool.workerGroup.Done()
+		// 除非任务池结束全部worker、上下文被取消或者worker数量超过任务并发数，否则将会一直从队列取值，队列为空时阻塞等待
+		for {
 			// 从队列取值
-			task := pool.taskQueue.poll()
-			if task == zero {
-				continue
+			entry, ok := pool.takeEntry()
+			if !ok {
+				return
 			}
-			// 将当前任务存入当前正在运行的任务集合中
-			pool.runningTasks.add(task)
-			// 延迟执行
-			if pool.workerExecuteDelay > 0 {
-				time.Sleep(pool.workerExecuteDelay)
+			task := entry.task
+			entry.attempts++
+			// 等待执行延迟、速率限制以及权重容量，可以执行时当前任务会被存入正在运行的任务集合中，任务池被中断时任务会被放回队列
+			if !pool.waitForExecution(entry) {
+				continue
 			}
-			// 执行任务
-			worker.run(task, worker.taskPool)
-			// 执行完成后，从当前任务列表移除
-			pool.runningTasks.remove(task)
+			// 执行任务，并记录失败，超时的任务会在其回调函数返回之后才处理执行结果
+			pool.notifyStart(entry, worker.id)
+			startTime := time.Now()
+			executeTask(&pool.basePool, task, func(ctx context.Context) (struct{}, error) {
+				return struct{}{}, worker.run(ctx, task, worker.taskPool)
+			}, func(_ struct{}, e error) {
+				worker.complete(entry, startTime, e)
+			})
 		}
 	}()
+}
+
+// 处理一个任务的执行结果，需要在任务执行回调函数返回之后调用
+//
+//   - entry 执行完成的任务条目
+//   - startTime 本次执行任务的开始时间
+//   - e 任务执行的错误
+func (worker *worker[T]) complete(entry *taskEntry[T], startTime time.Time, e error) {
+	pool := worker.taskPool
+	duration := time.Since(startTime)
+	pool.observeExecution(duration, e)
+	pool.releaseLimits(entry)
+	// 最终执行完成（不再重试）的任务，在依赖关系图中标记其执行结果
+	if pool.completeExecution(entry, worker.id, duration, e) {
+		pool.completeDependencies(entry, e == nil)
+	}
+	// 执行完成后，从当前任务列表移除
+	pool.runningTasks.remove(entry)
+	pool.finish()
+	pool.propagateIfPanic(e)
 }
\ No newline at end of file